@@ -0,0 +1,292 @@
+package godump
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultBytesPerLine = 16
+	defaultByteGroup    = 8
+	defaultMaxBytes     = 4096
+)
+
+// ByteView selects how byte slices and arrays are dumped.
+type ByteView int
+
+const (
+	// BytesHexdump renders the bytes as a table of hex and ASCII columns.
+	BytesHexdump ByteView = iota
+	// BytesAuto renders bytes holding printable UTF-8 text as a string and
+	// other bytes as a hex dump.
+	BytesAuto
+	// BytesBase64 renders the bytes in standard base64.
+	BytesBase64
+	// BytesLiteral renders the bytes as a Go []byte literal.
+	BytesLiteral
+)
+
+// WithByteView sets how byte slices and arrays are dumped. Defaults to
+// BytesHexdump.
+func WithByteView(view ByteView) Option {
+	return func(d *Dumper) {
+		d.byteView = view
+	}
+}
+
+// WithHexdumpLayout sets the number of bytes on each row of a hex dump and
+// the size of the groups separated by an extra space within a row. A group
+// size of 0 disables grouping. Defaults to 16 bytes in groups of 8.
+func WithHexdumpLayout(bytesPerLine, group int) Option {
+	return func(d *Dumper) {
+		if bytesPerLine > 0 {
+			d.bytesPerLine = bytesPerLine
+		}
+		d.byteGroup = max(group, 0)
+	}
+}
+
+// WithMaxBytes sets how many bytes of a byte slice or array are rendered
+// before the rest is truncated. Defaults to 4096.
+func WithMaxBytes(n int) Option {
+	return func(d *Dumper) {
+		d.maxBytes = n
+	}
+}
+
+// WithByteSniffing turns on or off the annotation of byte slices and arrays
+// whose content looks like JSON, protobuf or gzip data. It is on by default.
+func WithByteSniffing(enabled bool) Option {
+	return func(d *Dumper) {
+		d.byteSniffing = enabled
+	}
+}
+
+// printBytes renders the bytes of a byte slice or array in the view selected
+// by the options. Only the bytes shown are sniffed and checked for text, so
+// WithMaxBytes also bounds the work done for large slices.
+func (s *dumpState) printBytes(n Node, b []byte) {
+	if s.scanning {
+		return
+	}
+	limit := min(len(b), max(s.maxBytes, 0))
+	shown, more := b[:limit], limit < len(b)
+	text := shown // shown, without a rune cut in two by the truncation
+	for more && len(text) > 0 && !utf8.RuneStart(b[len(text)]) {
+		text = text[:len(text)-1]
+	}
+	printable := printableText(text)
+	if s.byteSniffing {
+		n.Content = sniffBytes(shown, printable, more)
+	}
+
+	view := s.byteView
+	if view == BytesAuto {
+		view = BytesHexdump
+		if len(b) > 0 && printable {
+			s.printByteText(n, `"`+s.scalarText(reflect.ValueOf(string(text)))+`"`, more)
+			return
+		}
+	}
+	switch view {
+	case BytesBase64:
+		s.printByteText(n, base64.StdEncoding.EncodeToString(shown), more)
+	case BytesLiteral:
+		s.printByteText(n, byteLiteral(shown, more), false)
+	default:
+		s.printHexDump(n, shown, more)
+	}
+}
+
+// printByteText renders bytes converted to text as a single scalar, marked
+// with an ellipsis if they were truncated.
+func (s *dumpState) printByteText(n Node, text string, truncated bool) {
+	if truncated {
+		text += "…"
+	}
+	n.Form = FormFormatted
+	s.r.Scalar(n, text)
+}
+
+// printHexDump renders bytes as a hex dump with ASCII representation,
+// followed by a truncation marker if more bytes were left out.
+func (s *dumpState) printHexDump(n Node, b []byte, truncated bool) {
+	const digits = "0123456789abcdef"
+
+	lineLen, group := s.bytesPerLine, s.byteGroup
+	n.Form = FormHexdump
+	s.r.BeginValue(n)
+	hex := make([]byte, 0, lineLen*3+lineLen/max(group, 1))
+	ascii := make([]byte, lineLen)
+	for i := 0; i < len(b); i += lineLen {
+		line := b[i:min(i+lineLen, len(b))]
+
+		h := hex[:0]
+		for j := range lineLen {
+			if j < len(line) {
+				h = append(h, digits[line[j]>>4], digits[line[j]&0x0f], ' ')
+			} else {
+				h = append(h, "   "...)
+			}
+			if group > 0 && (j+1)%group == 0 && j+1 < lineLen {
+				h = append(h, ' ')
+			}
+		}
+		for j := range ascii {
+			switch {
+			case j >= len(line):
+				ascii[j] = ' '
+			case line[j] >= 32 && line[j] <= 126:
+				ascii[j] = line[j]
+			default:
+				ascii[j] = '.'
+			}
+		}
+
+		s.r.HexRow(i, string(h), string(ascii))
+	}
+	if truncated {
+		s.r.Truncated(TruncatedItems)
+	}
+	s.r.EndValue(n)
+}
+
+// byteLiteral formats b as a Go []byte literal.
+func byteLiteral(b []byte, truncated bool) string {
+	const digits = "0123456789abcdef"
+
+	var sb strings.Builder
+	sb.Grow(len("[]byte{}") + len(b)*len("0x00, "))
+	sb.WriteString("[]byte{")
+	for i, c := range b {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.Write([]byte{'0', 'x', digits[c>>4], digits[c&0x0f]})
+	}
+	if truncated {
+		if len(b) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("...")
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// printableText reports whether b is valid UTF-8 made of printable characters
+// and whitespace only.
+func printableText(b []byte) bool {
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			return false
+		}
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+		b = b[size:]
+	}
+	return true
+}
+
+// sniffBytes returns "json", "gzip" or "protobuf" if b looks like data in
+// that format, and "" otherwise. printable tells whether b is text, and
+// truncated whether b is only the start of the data, in which case only the
+// shape of that start is checked. The checks are heuristics: they only look
+// at the shape of the data.
+func sniffBytes(b []byte, printable, truncated bool) string {
+	switch {
+	case len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b:
+		return "gzip"
+	case looksLikeJSON(b, truncated):
+		return "json"
+	case !printable && looksLikeProtobuf(b, truncated):
+		return "protobuf"
+	default:
+		return ""
+	}
+}
+
+// looksLikeJSON reports whether b is a JSON object or array, or, if b is
+// truncated, whether it opens like one.
+func looksLikeJSON(b []byte, truncated bool) bool {
+	t := bytes.TrimSpace(b)
+	if len(t) < 2 || (t[0] != '{' && t[0] != '[') {
+		return false
+	}
+	if !truncated {
+		return json.Valid(t)
+	}
+	next := bytes.TrimLeft(t[1:], " \t\r\n")
+	if len(next) == 0 {
+		return true
+	}
+	if t[0] == '{' {
+		return next[0] == '"' || next[0] == '}'
+	}
+	return bytes.IndexByte([]byte(`{["-0123456789tfn]`), next[0]) >= 0
+}
+
+// looksLikeProtobuf reports whether b parses as a sequence of protobuf wire
+// format fields, with valid field numbers and wire types, that ends exactly
+// at the end of b. If b is truncated, its last field may be cut short.
+func looksLikeProtobuf(b []byte, truncated bool) bool {
+	if len(b) < 2 {
+		return false
+	}
+	for len(b) > 0 {
+		key, n := protoVarint(b)
+		if n == 0 {
+			return truncated && len(b) < 10
+		}
+		if key>>3 == 0 || key>>3 > 1<<29-1 {
+			return false
+		}
+		b = b[n:]
+		switch key & 7 {
+		case 0: // varint
+			if _, n = protoVarint(b); n == 0 {
+				return truncated && len(b) < 10
+			}
+		case 1: // 64-bit
+			n = 8
+		case 2: // length-delimited
+			size, m := protoVarint(b)
+			if m == 0 {
+				return truncated && len(b) < 10
+			}
+			if size > uint64(len(b)-m) {
+				return truncated
+			}
+			n = m + int(size)
+		case 5: // 32-bit
+			n = 4
+		default:
+			return false
+		}
+		if n > len(b) {
+			return truncated
+		}
+		b = b[n:]
+	}
+	return true
+}
+
+// protoVarint decodes a varint from the start of b and returns it with its
+// length in bytes, or a length of 0 if b does not start with a valid varint.
+func protoVarint(b []byte) (uint64, int) {
+	var x uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		x |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i] < 0x80 {
+			return x, i + 1
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,126 @@
+package godump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytes_DefaultHexdump(t *testing.T) {
+	out := New(WithColor(false)).DumpStr([]byte("hi\x00"))
+
+	assert.Contains(t, out, "([]uint8) (len=3 cap=3) {\n")
+	assert.Contains(t, out, "00000000  68 69 00 ")
+	assert.Contains(t, out, "| hi.")
+}
+
+func TestBytes_Arrays(t *testing.T) {
+	type digest [4]byte
+	type holder struct{ sum digest }
+
+	out := New(WithColor(false)).DumpStr(holder{sum: digest{0xde, 0xad, 0xbe, 0xef}})
+	assert.Contains(t, out, "(godump.digest) (len=4) {")
+	assert.Contains(t, out, "00000000  de ad be ef ")
+
+	out = New(WithColor(false), WithByteView(BytesBase64)).DumpStr([3]byte{'h', 'i', '!'})
+	assert.Contains(t, out, "aGkh #[3]uint8")
+
+	out = New(WithColor(false)).DumpStr(map[[2]byte]int{{1, 2}: 3})
+	assert.Contains(t, out, "[1, 2] => 3", "array map keys stay inline")
+}
+
+func TestBytes_HexdumpLayout(t *testing.T) {
+	out := New(WithColor(false), WithHexdumpLayout(4, 2)).DumpStr([]byte("abcdef"))
+
+	assert.Contains(t, out, "00000000  61 62  63 64  | abcd |")
+	assert.Contains(t, out, "00000004  65 66         | ef   |")
+
+	out = New(WithColor(false), WithHexdumpLayout(4, 0)).DumpStr([]byte("abcd"))
+	assert.Contains(t, out, "00000000  61 62 63 64  | abcd |")
+}
+
+func TestBytes_MaxBytes(t *testing.T) {
+	data := bytes.Repeat([]byte{0xff}, 40)
+
+	out := New(WithColor(false), WithMaxBytes(20)).DumpStr(data)
+	assert.Contains(t, out, "(len=40 cap=40)")
+	assert.Contains(t, out, "00000010  ff ff ff ff ")
+	assert.NotContains(t, out, "00000020")
+	assert.Contains(t, out, "... (truncated)")
+
+	out = New(WithColor(false), WithMaxBytes(2), WithByteView(BytesLiteral)).DumpStr(data)
+	assert.Contains(t, out, "[]byte{0xff, 0xff, ...} #[]uint8")
+}
+
+func TestBytes_Auto(t *testing.T) {
+	d := New(WithColor(false), WithByteView(BytesAuto))
+
+	assert.Contains(t, d.DumpStr([]byte("héllo\n")), `"héllo\n" #[]uint8`)
+	assert.Contains(t, d.DumpStr([]byte{0xff, 0xfe}), "ff fe")
+	assert.Contains(t, d.DumpStr([]byte{}), "(len=0 cap=0) {")
+
+	// a truncated string keeps whole runes
+	out := New(WithColor(false), WithByteView(BytesAuto), WithMaxBytes(2)).DumpStr([]byte("héllo"))
+	assert.Contains(t, out, `"h"… #[]uint8`)
+}
+
+func TestBytes_Base64AndLiteral(t *testing.T) {
+	data := []byte("hi!")
+
+	out := New(WithColor(false), WithByteView(BytesBase64)).DumpStr(data)
+	assert.Contains(t, out, "aGkh #[]uint8")
+
+	out = New(WithColor(false), WithByteView(BytesLiteral)).DumpStr(data)
+	assert.Contains(t, out, "[]byte{0x68, 0x69, 0x21} #[]uint8")
+}
+
+func TestBytes_Sniffing(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, err := w.Write([]byte("payload"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	d := New(WithColor(false))
+	assert.Contains(t, d.DumpStr([]byte(` {"a": [1, 2]}`)), "{ // looks like json")
+	assert.Contains(t, d.DumpStr(gz.Bytes()), "{ // looks like gzip")
+	// field 1 = 150, field 2 = "hi"
+	assert.Contains(t, d.DumpStr([]byte{0x08, 0x96, 0x01, 0x12, 0x02, 'h', 'i'}), "{ // looks like protobuf")
+	assert.NotContains(t, d.DumpStr([]byte("plain text")), "looks like")
+	assert.NotContains(t, d.DumpStr([]byte{0x07, 0xff}), "looks like")
+
+	out := New(WithColor(false), WithByteSniffing(false)).DumpStr([]byte(`[1]`))
+	assert.NotContains(t, out, "looks like")
+}
+
+func TestBytes_OnlyShownBytesAreSniffed(t *testing.T) {
+	d := New(WithColor(false), WithMaxBytes(16))
+
+	// a JSON document cut short is recognized by its opening
+	doc := []byte(`{"items": [` + strings.Repeat(`1, `, 100) + `1]}`)
+	assert.Contains(t, d.DumpStr(doc), "{ // looks like json")
+	assert.NotContains(t, d.DumpStr([]byte(`{ not json at all, just braces }`)), "looks like")
+
+	// a protobuf message whose long string field is cut short
+	msg := append([]byte{0x08, 0x96, 0x01, 0x12, 0x40}, bytes.Repeat([]byte{0x80}, 64)...)
+	assert.Contains(t, d.DumpStr(msg), "{ // looks like protobuf")
+
+	// bytes past the shown ones do not decide whether the start is text
+	text := append(bytes.Repeat([]byte("a"), 32), 0xff)
+	out := New(WithColor(false), WithMaxBytes(16), WithByteView(BytesAuto)).DumpStr(text)
+	assert.Contains(t, out, `"aaaaaaaaaaaaaaaa"… #[]uint8`)
+}
+
+func TestBytes_JSONTree(t *testing.T) {
+	var tree map[string]any
+	require.NoError(t, json.Unmarshal([]byte(DumpJSONTree([]byte(`[1]`))), &tree))
+
+	assert.Equal(t, "hexdump", tree["form"])
+	assert.Equal(t, "5b315d", tree["value"])
+	assert.Equal(t, "json", tree["content"])
+}
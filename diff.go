@@ -0,0 +1,389 @@
+package godump
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Diff renders a unified tree of the differences between a and b.
+func Diff(a, b any) string {
+	return defaultDumper.Diff(a, b)
+}
+
+// Fdiff writes a unified tree of the differences between a and b to w.
+func Fdiff(w io.Writer, a, b any) {
+	defaultDumper.Fdiff(w, a, b)
+}
+
+// Diff renders a unified tree of the differences between a and b.
+func (d *Dumper) Diff(a, b any) string {
+	var sb strings.Builder
+	d.Fdiff(&sb, a, b)
+	return sb.String()
+}
+
+// Fdiff writes a unified tree of the differences between a and b to w. Both
+// values are walked like Dump walks them, unexported fields included. Struct
+// fields are matched by name, map entries by key and slice elements by index;
+// lines only in a are prefixed with "-", lines only in b with "+".
+func (d *Dumper) Fdiff(w io.Writer, a, b any) {
+	bw := getBufWriter(w)
+	defer putBufWriter(bw)
+	p := newDiffPrinter(bw, d.theme, d.colorModeFor(w))
+	d.printDumpHeader(p.r, 3)
+	p.diff(d.record(a), d.record(b))
+	bw.Flush()
+}
+
+// record walks v and returns the tree of entries it renders as.
+func (d *Dumper) record(v any) *diffEntry {
+	rec := &diffRecorder{}
+	d.writeDump(rec, v)
+	return rec.root
+}
+
+// entryKind is the renderer event a diffEntry was recorded from.
+type entryKind int
+
+const (
+	entryScalar    entryKind = iota // Scalar
+	entryValue                      // BeginValue through EndValue
+	entryReference                  // Reference
+	entryDepth                      // Truncated(TruncatedDepth)
+	entryItems                      // Truncated(TruncatedItems)
+	entryRepeat                     // Truncated(TruncatedRepeat)
+	entryHexRow                     // HexRow
+)
+
+// labelKind is how a diffEntry is introduced within its parent.
+type labelKind int
+
+const (
+	labelNone labelKind = iota
+	labelField
+	labelKey
+	labelIndex
+)
+
+// diffEntry is a recorded value, along with the struct field, map key or
+// slice index that introduced it.
+type diffEntry struct {
+	label    labelKind
+	name     string // field name
+	mapKey   *diffEntry
+	exported bool
+	index    int // slice index or hexdump offset
+	kind     entryKind
+	node     Node
+	text     string // scalar text or hexdump bytes
+	ascii    string // hexdump ASCII column
+	ref      int
+	children []*diffEntry
+}
+
+// key identifies the entry among its siblings.
+func (e *diffEntry) key() string {
+	switch {
+	case e.label == labelField:
+		return "." + e.name
+	case e.label == labelKey:
+		return "[" + e.mapKey.signature() + "]"
+	case e.label == labelIndex, e.kind == entryHexRow:
+		return "#" + strconv.Itoa(e.index)
+	case e.kind == entryItems:
+		return "..."
+	default:
+		return ""
+	}
+}
+
+// signature returns a text uniquely describing how e renders, ignoring &N markers.
+func (e *diffEntry) signature() string {
+	var sb strings.Builder
+	var write func(e *diffEntry)
+	write = func(e *diffEntry) {
+		fmt.Fprintf(&sb, "%d:%v:%q:%q:%d(", e.kind, e.node.Type, e.text, e.ascii, e.ref)
+		for _, c := range e.children {
+			sb.WriteString(c.key())
+			write(c)
+		}
+		sb.WriteString(")")
+	}
+	write(e)
+	return sb.String()
+}
+
+// equalEntries reports whether a and b render identically, ignoring &N markers.
+func equalEntries(a, b *diffEntry) bool {
+	na, nb := a.node, b.node
+	na.ID, nb.ID = 0, 0
+	na.Width, nb.Width = 0, 0
+	if a.kind != b.kind || na != nb || a.text != b.text || a.ascii != b.ascii ||
+		a.ref != b.ref || len(a.children) != len(b.children) {
+		return false
+	}
+	for i := range a.children {
+		if a.children[i].key() != b.children[i].key() || !equalEntries(a.children[i], b.children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// pairEntries matches the children of two values by key. Children of a come
+// first in their order, paired with their counterpart in b if any, followed
+// by the children only b has.
+func pairEntries(a, b []*diffEntry) [][2]*diffEntry {
+	keys := func(es []*diffEntry) []string {
+		seen := map[string]int{}
+		ks := make([]string, len(es))
+		for i, e := range es {
+			k := e.key()
+			ks[i] = k + "\x00" + strconv.Itoa(seen[k])
+			seen[k]++
+		}
+		return ks
+	}
+	ka, kb := keys(a), keys(b)
+	inB := make(map[string]int, len(b))
+	for j, k := range kb {
+		inB[k] = j
+	}
+
+	pairs := make([][2]*diffEntry, 0, max(len(a), len(b)))
+	matched := make([]bool, len(b))
+	for i, e := range a {
+		if j, ok := inB[ka[i]]; ok {
+			pairs = append(pairs, [2]*diffEntry{e, b[j]})
+			matched[j] = true
+		} else {
+			pairs = append(pairs, [2]*diffEntry{e, nil})
+		}
+	}
+	for j, e := range b {
+		if !matched[j] {
+			pairs = append(pairs, [2]*diffEntry{nil, e})
+		}
+	}
+	return pairs
+}
+
+// diffRecorder is a Renderer building a tree of diffEntries.
+type diffRecorder struct {
+	root    *diffEntry
+	stack   []*diffEntry
+	pending *diffEntry // entry announced by StructField, MapValue or SliceIndex
+}
+
+// add places e in the innermost open value, or makes it the root.
+func (r *diffRecorder) add(e *diffEntry) {
+	if len(r.stack) == 0 {
+		r.root = e
+		return
+	}
+	parent := r.stack[len(r.stack)-1]
+	parent.children = append(parent.children, e)
+}
+
+// child returns the entry for the next value, as announced by the last
+// StructField, MapValue or SliceIndex.
+func (r *diffRecorder) child(kind entryKind) *diffEntry {
+	e := r.pending
+	r.pending = nil
+	if e == nil {
+		e = &diffEntry{}
+	}
+	e.kind = kind
+	r.add(e)
+	return e
+}
+
+func (r *diffRecorder) Header(string, int) {}
+
+func (r *diffRecorder) BeginValue(n Node) {
+	e := r.child(entryValue)
+	e.node = n
+	r.stack = append(r.stack, e)
+}
+
+func (r *diffRecorder) EndValue(Node) {
+	r.stack = r.stack[:len(r.stack)-1]
+}
+
+func (r *diffRecorder) StructField(name string, exported bool) {
+	r.pending = &diffEntry{label: labelField, name: name, exported: exported}
+}
+
+// MapKey records the key of the entry as the child of a placeholder entry.
+func (r *diffRecorder) MapKey() {
+	r.stack = append(r.stack, &diffEntry{kind: entryValue})
+}
+
+func (r *diffRecorder) MapValue() {
+	holder := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	r.pending = &diffEntry{label: labelKey, mapKey: holder.children[0]}
+}
+
+func (r *diffRecorder) SliceIndex(index int) {
+	r.pending = &diffEntry{label: labelIndex, index: index}
+}
+
+func (r *diffRecorder) HexRow(offset int, hex, ascii string) {
+	r.add(&diffEntry{kind: entryHexRow, index: offset, text: hex, ascii: ascii})
+}
+
+func (r *diffRecorder) Scalar(n Node, text string) {
+	e := r.child(entryScalar)
+	e.node, e.text = n, text
+}
+
+func (r *diffRecorder) Reference(id int) {
+	r.child(entryReference).ref = id
+}
+
+func (r *diffRecorder) Truncated(t Truncation) {
+	switch t {
+	case TruncatedItems:
+		r.add(&diffEntry{kind: entryItems})
+	case TruncatedRepeat:
+		r.child(entryRepeat)
+	default:
+		r.child(entryDepth)
+	}
+}
+
+// diffMark tells whether a line of a diff is shared by both values or only
+// in one of them.
+type diffMark int
+
+const (
+	markSame diffMark = iota
+	markRemoved
+	markAdded
+)
+
+// diffStyle is the styling of the lines with a given mark.
+type diffStyle struct {
+	open, close [roleCount]string
+	gutter      string
+}
+
+// diffPrinter renders a diff by replaying recorded entries through a text
+// renderer, switching its styles and gutter with the mark of each line.
+type diffPrinter struct {
+	r      *textRenderer
+	styles [3]diffStyle
+	cur    diffMark
+}
+
+// newDiffPrinter creates a printer writing to w. Shared lines use the colors
+// of the theme; removed and added lines are shown entirely in its Removed and
+// Added colors.
+func newDiffPrinter(w io.Writer, theme Theme, mode ColorMode) *diffPrinter {
+	p := &diffPrinter{r: &textRenderer{w: w, escape: identity}}
+
+	same := &p.styles[markSame]
+	same.open, same.close = theme.ansiStyles(mode)
+	same.gutter = "  "
+
+	for mark, c := range map[diffMark]Color{markRemoved: theme.Removed, markAdded: theme.Added} {
+		s := &p.styles[mark]
+		if seq := ansiColor(c, mode); mode != ColorNone && seq != "" {
+			for i := range roleCount {
+				s.open[i], s.close[i] = seq, ansiReset
+			}
+		}
+		symbol := "-"
+		if mark == markAdded {
+			symbol = "+"
+		}
+		s.gutter = s.open[roleNone] + symbol + " " + s.close[roleNone]
+	}
+
+	p.r.open, p.r.close = same.open, same.close
+	return p
+}
+
+// mark styles the lines rendered next.
+func (p *diffPrinter) mark(m diffMark) {
+	s := &p.styles[m]
+	p.cur = m
+	p.r.open, p.r.close, p.r.gutter = s.open, s.close, s.gutter
+}
+
+// diff renders the differences between a and b, either of which may be nil.
+func (p *diffPrinter) diff(a, b *diffEntry) {
+	switch {
+	case a == nil:
+		p.replay(b, markAdded)
+	case b == nil:
+		p.replay(a, markRemoved)
+	case equalEntries(a, b):
+		p.replay(b, markSame)
+	case a.kind == entryValue && b.kind == entryValue &&
+		a.node.Type == b.node.Type && a.node.Form == b.node.Form:
+		p.mark(markSame)
+		p.label(b)
+		n := b.node
+		n.Width = max(a.node.Width, b.node.Width)
+		p.r.BeginValue(n)
+		for _, pair := range pairEntries(a.children, b.children) {
+			p.diff(pair[0], pair[1])
+		}
+		p.mark(markSame)
+		p.r.EndValue(b.node)
+	default:
+		p.replay(a, markRemoved)
+		p.replay(b, markAdded)
+	}
+}
+
+// label renders what introduces the value of e: its field name, map key or
+// slice index, or the gutter of a top-level value.
+func (p *diffPrinter) label(e *diffEntry) {
+	switch e.label {
+	case labelField:
+		p.r.StructField(e.name, e.exported)
+	case labelKey:
+		m := p.cur
+		p.r.MapKey()
+		p.replay(e.mapKey, m)
+		p.r.MapValue()
+	case labelIndex:
+		p.r.SliceIndex(e.index)
+	default:
+		if p.r.depth == 0 {
+			io.WriteString(p.r.w, p.r.gutter)
+		}
+	}
+}
+
+// replay renders e and everything below it with the given mark.
+func (p *diffPrinter) replay(e *diffEntry, m diffMark) {
+	p.mark(m)
+	p.label(e)
+	switch e.kind {
+	case entryScalar:
+		p.r.Scalar(e.node, e.text)
+	case entryValue:
+		p.r.BeginValue(e.node)
+		for _, c := range e.children {
+			p.replay(c, m)
+		}
+		p.mark(m)
+		p.r.EndValue(e.node)
+	case entryReference:
+		p.r.Reference(e.ref)
+	case entryDepth:
+		p.r.Truncated(TruncatedDepth)
+	case entryItems:
+		p.r.Truncated(TruncatedItems)
+	case entryRepeat:
+		p.r.Truncated(TruncatedRepeat)
+	case entryHexRow:
+		p.r.HexRow(e.index, e.text, e.ascii)
+	}
+}
@@ -0,0 +1,99 @@
+package godump
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// diffLines returns the lines of the uncolored diff of a and b, without the header.
+func diffLines(a, b any) []string {
+	out := New(WithColor(false)).Diff(a, b)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	return lines[1:]
+}
+
+func TestDiff_ChangedField(t *testing.T) {
+	type State struct {
+		Name  string
+		Count int
+	}
+	lines := diffLines(State{Name: "idle", Count: 1}, State{Name: "running", Count: 1})
+
+	assert.Equal(t, []string{
+		"  #godump.State ",
+		`-   +Name  => "idle"`,
+		`+   +Name  => "running"`,
+		"    +Count => 1",
+		"  }",
+	}, lines)
+}
+
+func TestDiff_UnexportedFields(t *testing.T) {
+	type conn struct{ state string }
+
+	out := New(WithColor(false)).Diff(conn{state: "open"}, conn{state: "closed"})
+
+	assert.Contains(t, out, `-   -state => "open"`)
+	assert.Contains(t, out, `+   -state => "closed"`)
+}
+
+func TestDiff_MapKeys(t *testing.T) {
+	out := New(WithColor(false)).Diff(map[string]int{"gone": 1}, map[string]int{"new": 2})
+
+	assert.Contains(t, out, `-    "gone" => 1`)
+	assert.Contains(t, out, `+    "new" => 2`)
+}
+
+func TestDiff_SliceElements(t *testing.T) {
+	lines := diffLines([]string{"a", "b"}, []string{"a", "c", "d"})
+
+	assert.Equal(t, []string{
+		"  [",
+		`    0 => "a"`,
+		`-   1 => "b"`,
+		`+   1 => "c"`,
+		`+   2 => "d"`,
+		"  ]",
+	}, lines)
+}
+
+func TestDiff_RemovedElementsShownWhole(t *testing.T) {
+	type Item struct{ ID int }
+	out := New(WithColor(false)).Diff([]Item{{ID: 1}, {ID: 2}}, []Item{{ID: 1}})
+
+	assert.Contains(t, out, "-   1 => #godump.Item ")
+	assert.Contains(t, out, "-     +ID => 2")
+	assert.Contains(t, out, "-   }")
+}
+
+func TestDiff_TypeChange(t *testing.T) {
+	lines := diffLines(1, "1")
+
+	assert.Equal(t, []string{"- 1", `+ "1"`}, lines)
+}
+
+func TestDiff_EqualValues(t *testing.T) {
+	out := New(WithColor(false)).Diff(map[string]int{"a": 1}, map[string]int{"a": 1})
+
+	assert.NotContains(t, out, "\n-")
+	assert.NotContains(t, out, "\n+")
+	assert.Contains(t, out, `     "a" => 1`)
+}
+
+func TestDiff_Colors(t *testing.T) {
+	out := New(WithColorMode(ColorTrue)).Diff(1, 2)
+
+	assert.Contains(t, out, ansiColor(ThemeDark.Removed, ColorTrue)+"- ")
+	assert.Contains(t, out, ansiColor(ThemeDark.Added, ColorTrue)+"+ ")
+}
+
+func TestFdiff(t *testing.T) {
+	var sb strings.Builder
+	Fdiff(&sb, true, false)
+
+	assert.Contains(t, sb.String(), "<#dump //")
+	assert.Contains(t, sb.String(), "true")
+	assert.Contains(t, sb.String(), "false")
+}
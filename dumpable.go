@@ -0,0 +1,51 @@
+package godump
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Dumpable is implemented by types that control their own dump output.
+// DumpValue returns the value dumped in place of the receiver, such as a
+// summary struct or map, so a cache can show its hit ratio and size instead
+// of its internal shards. It is checked before the value is walked by
+// reflection; WithRawView dumps the receiver itself instead.
+type Dumpable interface {
+	DumpValue() any
+}
+
+// WithRawView makes Dumpable values dump their own fields instead of the
+// value returned by DumpValue.
+func WithRawView(enabled bool) Option {
+	return func(d *Dumper) {
+		d.rawView = enabled
+	}
+}
+
+// printDumpable renders the value returned by DumpValue in place of v and
+// reports whether v is Dumpable. Nil values are left to the default nil
+// rendering, and a DumpValue returning the receiver's own type is dumped
+// normally. The scanning pass does not call DumpValue, so it runs once per
+// value and dump.
+func (s *dumpState) printDumpable(v reflect.Value, depth int) bool {
+	if s.rawView || !v.IsValid() || isNil(v) || slices.Contains(s.proxied, v.Type()) ||
+		!implements(v, implDumpable) {
+		return false
+	}
+	val := forceExported(v)
+	if !val.CanInterface() {
+		return false
+	}
+	dv, ok := val.Interface().(Dumpable)
+	if !ok {
+		return false
+	}
+	if s.scanning {
+		return true
+	}
+
+	s.proxied = append(s.proxied, v.Type())
+	s.printValue(reflect.ValueOf(dv.DumpValue()), depth)
+	s.proxied = s.proxied[:len(s.proxied)-1]
+	return true
+}
@@ -0,0 +1,89 @@
+package godump
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shardedCache struct {
+	shards [2]map[string]int
+	hits   int
+	misses int
+}
+
+func (c *shardedCache) DumpValue() any {
+	return struct {
+		Size     int
+		HitRatio float64
+	}{len(c.shards[0]) + len(c.shards[1]), float64(c.hits) / float64(c.hits+c.misses)}
+}
+
+type selfDumpable struct{ N int }
+
+func (d selfDumpable) DumpValue() any { return d }
+
+type freshSummary struct{ n int }
+
+var freshSummaryCalls int
+
+func (f freshSummary) DumpValue() any {
+	freshSummaryCalls++
+	runtime.GC() // let the previous summary be collected and its address reused
+	return map[string]int{"n": f.n}
+}
+
+func newShardedCache() *shardedCache {
+	return &shardedCache{shards: [2]map[string]int{{"a": 1}, {"b": 2}}, hits: 3, misses: 1}
+}
+
+func TestDumpable(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(newShardedCache())
+
+	assert.Contains(t, out, "+Size     => 2")
+	assert.Contains(t, out, "+HitRatio => 0.75")
+	assert.NotContains(t, out, "shards")
+}
+
+func TestDumpable_Field(t *testing.T) {
+	type app struct {
+		cache *shardedCache
+	}
+	out := New(WithColor(false)).DumpStr(app{cache: newShardedCache()})
+
+	assert.Contains(t, out, "+HitRatio => 0.75")
+}
+
+func TestDumpable_NilReceiver(t *testing.T) {
+	out := New(WithColor(false)).DumpStr((*shardedCache)(nil))
+
+	assert.Contains(t, out, "*godump.shardedCache(nil)")
+}
+
+func TestDumpable_ReturnsItself(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(selfDumpable{N: 1})
+
+	assert.Contains(t, out, "+N => 1")
+}
+
+func TestDumpable_RawView(t *testing.T) {
+	out := New(WithColor(false), WithRawView(true)).DumpStr(newShardedCache())
+
+	assert.Contains(t, out, "-shards")
+	assert.Contains(t, out, "-hits   => 3")
+	assert.NotContains(t, out, "HitRatio")
+}
+
+func TestDumpable_TemporaryValuesAreNotBackReferences(t *testing.T) {
+	items := make([]freshSummary, 50)
+	for i := range items {
+		items[i].n = i
+	}
+	freshSummaryCalls = 0
+	out := New(WithColor(false)).DumpStr(items)
+
+	assert.NotContains(t, out, "↩︎")
+	assert.Contains(t, out, `"n" => 49`)
+	assert.Equal(t, len(items), freshSummaryCalls, "DumpValue runs once per dump")
+}
@@ -0,0 +1,227 @@
+package godump
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxDepth     = 15
+	defaultMaxItems     = 100
+	defaultMaxStringLen = 100000
+)
+
+// Dumper dumps values using its own set of options, so differently
+// configured dumps can coexist in the same process. Create one with New.
+type Dumper struct {
+	maxDepth       int
+	maxItems       int
+	maxStringLen   int
+	includeFields  []fieldPattern
+	excludeFields  []fieldPattern
+	sortMaps       bool
+	timeFormatting bool
+	timeLayout     string
+	timeLocation   *time.Location
+	relativeTime   bool
+	durationNanos  bool
+	errorRendering bool
+	rawView        bool
+	stringerMode   StringerMode
+	stringerTypes  []reflect.Type
+	byteView       ByteView
+	bytesPerLine   int
+	byteGroup      int
+	maxBytes       int
+	byteSniffing   bool
+	embeddedJSON   bool
+	embeddedXML    bool
+	redact         bool
+	sensitiveNames []string
+	secretPatterns []secretPattern
+	redactMask     string
+	colorMode      ColorMode
+	forceColor     bool
+	theme          Theme
+	newRenderer    RendererFunc
+	writer         io.Writer
+}
+
+// Option configures a Dumper.
+type Option func(*Dumper)
+
+// New creates a Dumper with the given options applied on top of the defaults.
+func New(opts ...Option) *Dumper {
+	d := &Dumper{
+		maxDepth:       defaultMaxDepth,
+		maxItems:       defaultMaxItems,
+		maxStringLen:   defaultMaxStringLen,
+		theme:          ThemeDark,
+		sortMaps:       true,
+		timeFormatting: true,
+		timeLayout:     time.RFC3339Nano,
+		durationNanos:  true,
+		errorRendering: true,
+		bytesPerLine:   defaultBytesPerLine,
+		byteGroup:      defaultByteGroup,
+		maxBytes:       defaultMaxBytes,
+		byteSniffing:   true,
+		embeddedJSON:   true,
+		redact:         true,
+		sensitiveNames: slices.Clone(defaultSensitiveNames),
+		secretPatterns: slices.Clone(defaultSecretPatterns),
+		redactMask:     defaultRedactMask,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// WithMaxDepth limits how deeply nested values are dumped.
+func WithMaxDepth(n int) Option {
+	return func(d *Dumper) {
+		d.maxDepth = n
+	}
+}
+
+// WithMaxItems limits how many slice, array and map items are dumped.
+func WithMaxItems(n int) Option {
+	return func(d *Dumper) {
+		d.maxItems = n
+	}
+}
+
+// WithMaxStringLen limits how many runes of a string are dumped.
+func WithMaxStringLen(n int) Option {
+	return func(d *Dumper) {
+		d.maxStringLen = n
+	}
+}
+
+// WithColor forces colorized output on or off. When on, the color mode is
+// still detected from the environment; see WithColorMode to set it directly.
+func WithColor(enabled bool) Option {
+	return func(d *Dumper) {
+		if enabled {
+			d.colorMode = ColorAuto
+			d.forceColor = true
+		} else {
+			d.colorMode = ColorNone
+			d.forceColor = false
+		}
+	}
+}
+
+// WithWriter sets the writer used by Dump and Dd. Defaults to os.Stdout.
+func WithWriter(w io.Writer) Option {
+	return func(d *Dumper) {
+		d.writer = w
+	}
+}
+
+// renderer creates the renderer for a single dump writing to w in the given color mode.
+func (d *Dumper) renderer(w io.Writer, mode ColorMode) Renderer {
+	switch {
+	case d.newRenderer != nil:
+		return d.newRenderer(w)
+	case mode == ColorNone:
+		return NewPlainRenderer(w)
+	default:
+		return NewANSIRenderer(w, d.theme, mode)
+	}
+}
+
+// out returns the writer used by Dump, resolving os.Stdout at call time.
+func (d *Dumper) out() io.Writer {
+	if d.writer != nil {
+		return d.writer
+	}
+	return os.Stdout
+}
+
+// Dump prints the values to the dumper's writer.
+func (d *Dumper) Dump(vs ...any) {
+	d.Fdump(d.out(), vs...)
+}
+
+// Fdump writes the formatted dump of values to the given io.Writer. The
+// output is streamed through a small buffer as the values are walked, so
+// memory use does not grow with the size of the dump.
+func (d *Dumper) Fdump(w io.Writer, vs ...any) {
+	bw := getBufWriter(w)
+	defer putBufWriter(bw)
+	r := d.renderer(bw, d.colorModeFor(w))
+	d.printDumpHeader(r, 3)
+	d.writeDump(r, vs...)
+	bw.Flush()
+}
+
+// bufPool recycles the buffers dumps are streamed through.
+var bufPool = sync.Pool{
+	New: func() any { return bufio.NewWriter(nil) },
+}
+
+// getBufWriter returns a pooled buffered writer writing to w.
+func getBufWriter(w io.Writer) *bufio.Writer {
+	bw := bufPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw
+}
+
+// putBufWriter returns bw to the pool.
+func putBufWriter(bw *bufio.Writer) {
+	bw.Reset(nil)
+	bufPool.Put(bw)
+}
+
+// DumpStr dumps the values as a string.
+func (d *Dumper) DumpStr(vs ...any) string {
+	var sb strings.Builder
+	d.Fdump(&sb, vs...)
+	return sb.String()
+}
+
+// DumpJSON dumps the values as a pretty-printed JSON string.
+// If there is more than one value, they are dumped as a JSON array.
+// Redaction applies to the marshalled output: the values of sensitive keys
+// and of fields tagged dump:"redact" are replaced by the mask, and secrets
+// are masked inside strings. It returns an error string if marshalling fails.
+func (d *Dumper) DumpJSON(vs ...any) string {
+	if len(vs) == 0 {
+		return `{"error": "DumpJSON called with no arguments"}`
+	}
+
+	var data any = vs
+	if len(vs) == 1 {
+		data = vs[0]
+	}
+
+	b, err := json.Marshal(data)
+	if err == nil && d.redact {
+		b, err = d.redactJSON(b, redactedJSONKeys(vs))
+	}
+	if err != nil {
+		return fmt.Sprintf(`{"error": "%s"}`, err.Error())
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", strings.Repeat(" ", indentWidth)); err != nil {
+		return fmt.Sprintf(`{"error": "%s"}`, err.Error())
+	}
+	return out.String()
+}
+
+// Dd dumps the values and exits the program.
+func (d *Dumper) Dd(vs ...any) {
+	d.Dump(vs...)
+	exitFunc(1)
+}
@@ -0,0 +1,217 @@
+package godump
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	d := New()
+	assert.Equal(t, defaultMaxDepth, d.maxDepth)
+	assert.Equal(t, defaultMaxItems, d.maxItems)
+	assert.Equal(t, defaultMaxStringLen, d.maxStringLen)
+}
+
+func TestDumper_IndependentOptions(t *testing.T) {
+	root := map[string]any{}
+	curr := root
+	for range 5 {
+		child := map[string]any{}
+		curr["child"] = child
+		curr = child
+	}
+
+	shallow := New(WithMaxDepth(2), WithColor(false))
+	deep := New(WithMaxDepth(20), WithColor(false))
+
+	assert.Contains(t, shallow.DumpStr(root), "... (max depth)")
+	assert.NotContains(t, deep.DumpStr(root), "... (max depth)")
+}
+
+func TestDumper_WithWriter(t *testing.T) {
+	var sb strings.Builder
+	d := New(WithWriter(&sb), WithColor(false))
+	d.Dump("hello")
+
+	assert.Contains(t, sb.String(), `"hello"`)
+	assert.Contains(t, sb.String(), "<#dump //")
+}
+
+func TestDumper_WithColor(t *testing.T) {
+	assert.NotContains(t, New(WithColor(false)).DumpStr(42), "\033[")
+	assert.Contains(t, New(WithColor(true)).DumpStr(42), "\033[")
+}
+
+func TestDumper_DumpHTMLIgnoresColorOption(t *testing.T) {
+	html := New(WithColor(false)).DumpHTML("x")
+	assert.Contains(t, html, `<span style="color:`)
+}
+
+func TestDumper_Dd(t *testing.T) {
+	orig := exitFunc
+	defer func() { exitFunc = orig }()
+
+	code := 0
+	exitFunc = func(c int) { code = c }
+
+	var sb strings.Builder
+	New(WithWriter(&sb)).Dd("bye")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, sb.String(), "bye")
+}
+
+func TestFdump_AlignsFieldsPerStruct(t *testing.T) {
+	type Inner struct{ LongFieldName int }
+	type Outer struct {
+		A     int
+		Inner Inner
+	}
+	out := New(WithColor(false)).DumpStr(Outer{})
+
+	assert.Contains(t, out, "\n  +A     => 0\n")
+	assert.Contains(t, out, "\n    +LongFieldName => 0\n")
+}
+
+// countingWriter counts the writes made to it.
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func TestFdump_Streams(t *testing.T) {
+	w := &countingWriter{}
+	New(WithColor(false), WithMaxItems(math.MaxInt)).Fdump(w, benchUsers(1000))
+
+	assert.Greater(t, w.writes, 1, "output should be flushed while the value is walked")
+}
+
+type benchAddress struct {
+	Street string
+	City   string
+}
+
+type benchUser struct {
+	ID      int
+	Name    string
+	Email   string
+	Active  bool
+	Address benchAddress
+	Tags    []string
+}
+
+func benchUsers(n int) []benchUser {
+	users := make([]benchUser, n)
+	for i := range users {
+		users[i] = benchUser{
+			ID:      i,
+			Name:    fmt.Sprintf("user-%d", i),
+			Email:   fmt.Sprintf("user-%d@example.com", i),
+			Active:  i%2 == 0,
+			Address: benchAddress{Street: "1 Main St", City: "Springfield"},
+			Tags:    []string{"a", "b"},
+		}
+	}
+	return users
+}
+
+// BenchmarkFdump_Large streams large values to io.Discard. Compare its B/op
+// with BenchmarkDumpStr_Large, which keeps the whole output in memory.
+func BenchmarkFdump_Large(b *testing.B) {
+	d := New(WithColor(false), WithMaxItems(math.MaxInt))
+	for _, n := range []int{1_000, 10_000} {
+		users := benchUsers(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				d.Fdump(io.Discard, users)
+			}
+		})
+	}
+}
+
+// BenchmarkDumpStr_Large renders the same values into a string.
+func BenchmarkDumpStr_Large(b *testing.B) {
+	d := New(WithColor(false), WithMaxItems(math.MaxInt))
+	for _, n := range []int{1_000, 10_000} {
+		users := benchUsers(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				_ = d.DumpStr(users)
+			}
+		})
+	}
+}
+
+func TestFdump_AllocationsStayLow(t *testing.T) {
+	users := benchUsers(100)
+	d := New(WithColor(false), WithMaxItems(math.MaxInt))
+	d.Fdump(io.Discard, users) // warm up the type caches and pools
+
+	allocs := testing.AllocsPerRun(10, func() {
+		d.Fdump(io.Discard, users)
+	})
+
+	assert.Less(t, allocs, float64(10*len(users)), "allocations per dumped struct regressed")
+}
+
+// benchNode is a linked list used to benchmark deeply nested values.
+type benchNode struct {
+	Value int
+	Next  *benchNode
+}
+
+func benchDump(b *testing.B, v any, opts ...Option) {
+	d := New(append([]Option{WithColor(false)}, opts...)...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		d.Fdump(io.Discard, v)
+	}
+}
+
+func BenchmarkDump_SmallStruct(b *testing.B) {
+	benchDump(b, benchUsers(1)[0])
+}
+
+func BenchmarkDump_DeepNesting(b *testing.B) {
+	var head *benchNode
+	for i := range 14 {
+		head = &benchNode{Value: i, Next: head}
+	}
+	benchDump(b, head)
+}
+
+func BenchmarkDump_LargeSlice(b *testing.B) {
+	s := make([]int, 100_000)
+	for i := range s {
+		s[i] = i
+	}
+	benchDump(b, s, WithMaxItems(math.MaxInt))
+}
+
+func BenchmarkDump_LargeMap(b *testing.B) {
+	m := make(map[string]int, 10_000)
+	for i := range 10_000 {
+		m[fmt.Sprintf("key-%05d", i)] = i
+	}
+	benchDump(b, m, WithMaxItems(math.MaxInt))
+}
+
+func BenchmarkDump_Bytes(b *testing.B) {
+	data := make([]byte, 1<<20)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	benchDump(b, data, WithMaxBytes(math.MaxInt))
+}
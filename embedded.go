@@ -0,0 +1,171 @@
+package godump
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// WithEmbeddedJSON turns on or off the decoding of json.RawMessage values
+// and strings holding a JSON object or array, which are then shown as the
+// decoded tree instead of a single escaped line. It is on by default.
+func WithEmbeddedJSON(enabled bool) Option {
+	return func(d *Dumper) {
+		d.embeddedJSON = enabled
+	}
+}
+
+// WithEmbeddedXML turns on or off the decoding of strings holding an XML
+// document, which are then shown as a tree of elements. It is off by default.
+func WithEmbeddedXML(enabled bool) Option {
+	return func(d *Dumper) {
+		d.embeddedXML = enabled
+	}
+}
+
+// XMLElement is an element of an XML document that WithEmbeddedXML decoded
+// from a string. Dumps show decoded documents as a tree of XMLElements.
+type XMLElement struct {
+	Name     string            // local name of the element
+	Attrs    map[string]string `dump:"omitempty"` // attributes by local name
+	Text     string            `dump:"omitempty"` // non-blank character data
+	Children []*XMLElement     `dump:"omitempty"` // child elements, in document order
+}
+
+// printEmbedded renders a json.RawMessage, or a string holding a JSON or XML
+// document, as the value decoded from it, and reports whether it did. The
+// decoded value is marked with the format it was decoded from. Map keys are
+// never decoded, so string keys stay quoted strings.
+func (s *dumpState) printEmbedded(v reflect.Value, depth int) bool {
+	if s.scanning || s.inKey || !v.IsValid() {
+		return false
+	}
+	var data string
+	switch {
+	case v.Type() == rawMessageType && s.embeddedJSON:
+		data = string(v.Bytes())
+	case v.Kind() == reflect.String && (s.embeddedJSON || s.embeddedXML) && !implements(v, implStringer):
+		data = v.String()
+	default:
+		return false
+	}
+	if len(data) > s.maxStringLen {
+		return false
+	}
+
+	var decoded any
+	var format string
+	switch t := strings.TrimSpace(data); {
+	case s.embeddedJSON && len(t) > 1 && (t[0] == '{' || t[0] == '['):
+		var ok bool
+		if decoded, ok = decodeJSON(t); !ok {
+			return false
+		}
+		format = "json"
+	case s.embeddedXML && v.Kind() == reflect.String && len(t) > 1 && t[0] == '<' && t[len(t)-1] == '>':
+		root, ok := decodeXML(t)
+		if !ok {
+			return false
+		}
+		decoded, format = root, "xml"
+	default:
+		return false
+	}
+
+	s.pendingDecoded = format
+	s.printValue(reflect.ValueOf(decoded), depth)
+	s.pendingDecoded = ""
+	return true
+}
+
+// decodeJSON decodes a JSON document, turning its numbers into int64 values
+// when they are integers and float64 values otherwise.
+func decodeJSON(data string) (any, bool) {
+	if !json.Valid([]byte(data)) {
+		return nil, false
+	}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	return jsonNumbers(v), true
+}
+
+// jsonNumbers replaces the json.Number values in v.
+func jsonNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = jsonNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = jsonNumbers(e)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return v
+}
+
+// decodeXML decodes an XML document made of a single root element, keeping
+// the non-blank text of each element.
+func decodeXML(data string) (*XMLElement, bool) {
+	dec := xml.NewDecoder(strings.NewReader(data))
+	var root *XMLElement
+	var open []*XMLElement
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return root, root != nil && len(open) == 0
+		}
+		if err != nil {
+			return nil, false
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if root != nil && len(open) == 0 {
+				return nil, false // a second root element
+			}
+			e := &XMLElement{Name: tok.Name.Local}
+			for _, a := range tok.Attr {
+				if e.Attrs == nil {
+					e.Attrs = map[string]string{}
+				}
+				e.Attrs[a.Name.Local] = a.Value
+			}
+			if len(open) > 0 {
+				parent := open[len(open)-1]
+				parent.Children = append(parent.Children, e)
+			} else {
+				root = e
+			}
+			open = append(open, e)
+		case xml.EndElement:
+			open = open[:len(open)-1]
+		case xml.CharData:
+			text := bytes.TrimSpace(tok)
+			if len(text) == 0 {
+				continue
+			}
+			if len(open) == 0 {
+				return nil, false // text outside the root element
+			}
+			open[len(open)-1].Text += string(text)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package godump
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Body string
+	Raw  json.RawMessage
+}
+
+func TestEmbedded_JSONString(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(payload{Body: ` {"user": {"name": "a", "token": "x"}, "n": [1, 2.5, null]} `})
+
+	assert.Contains(t, out, "+Body => { // decoded from json\n")
+	assert.Contains(t, out, `"name" => "a"`)
+	assert.Contains(t, out, `"token" => [REDACTED]`)
+	assert.Contains(t, out, "0 => 1\n")
+	assert.Contains(t, out, "1 => 2.500000\n")
+	assert.Contains(t, out, "2 => interface {}(nil)")
+}
+
+func TestEmbedded_RawMessage(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(payload{Raw: json.RawMessage(`["x"]`)})
+
+	assert.Contains(t, out, "+Raw  => [ // decoded from json\n")
+	assert.Contains(t, out, `0 => "x"`)
+}
+
+func TestEmbedded_LeavesOtherStrings(t *testing.T) {
+	d := New(WithColor(false))
+
+	assert.Contains(t, d.DumpStr("{not json"), `"{not json"`)
+	assert.Contains(t, d.DumpStr("42"), `"42"`)
+	assert.Contains(t, d.DumpStr("<a/>"), `"<a/>"`)
+
+	out := New(WithColor(false), WithEmbeddedJSON(false)).DumpStr(`{"a":1}`)
+	assert.Contains(t, out, `"{"a":1}"`)
+	assert.NotContains(t, out, "decoded")
+}
+
+func TestEmbedded_MapKeysStayStrings(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(map[string]string{"[1,2]": `{"a":1}`})
+
+	assert.Contains(t, out, `"[1,2]" => { // decoded from json`)
+}
+
+func TestEmbedded_XML(t *testing.T) {
+	d := New(WithColor(false), WithEmbeddedXML(true))
+
+	out := d.DumpStr(`<book id="7"><title>Go</title><draft/></book>`)
+	assert.Contains(t, out, "#godump.XMLElement // decoded from xml")
+	assert.Contains(t, out, `+Name     => "book"`)
+	assert.Contains(t, out, `"id" => "7"`)
+	assert.Contains(t, out, `+Text => "Go"`)
+	assert.Contains(t, out, `+Name => "draft"`)
+
+	assert.Contains(t, d.DumpStr("<a></a><b></b>"), `"<a></a><b></b>"`)
+	assert.Contains(t, d.DumpStr("<a>"), `"<a>"`)
+}
+
+func TestEmbedded_JSONTree(t *testing.T) {
+	var tree map[string]any
+	require.NoError(t, json.Unmarshal([]byte(DumpJSONTree(`{"a":1}`)), &tree))
+
+	assert.Equal(t, "json", tree["decoded"])
+	assert.Equal(t, "map", tree["kind"])
+}
@@ -0,0 +1,153 @@
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// stackMethods are the methods errors commonly expose their stack traces
+// through, such as StackTrace() in github.com/pkg/errors.
+var stackMethods = []string{"StackTrace", "Frames", "Callers"}
+
+var (
+	framesPtrType = reflect.TypeOf((*runtime.Frames)(nil))
+	frameType     = reflect.TypeOf(runtime.Frame{})
+)
+
+// WithErrorRendering turns the dedicated rendering of errors on or off. When
+// on, errors are shown with their message, the errors they wrap and their
+// stack trace instead of their fields. It is on by default.
+func WithErrorRendering(enabled bool) Option {
+	return func(d *Dumper) {
+		d.errorRendering = enabled
+	}
+}
+
+// printError renders an error as a tree of its concrete type, its Error()
+// message, the errors it wraps and any stack trace attached to it, and
+// reports whether v was an error.
+func (s *dumpState) printError(v reflect.Value, depth int) bool {
+	if !s.errorRendering || !v.IsValid() || !implements(v, implError) {
+		return false
+	}
+	val := forceExported(v)
+	if !val.CanInterface() {
+		return false
+	}
+	err, ok := val.Interface().(error)
+	if !ok {
+		return false
+	}
+	ev := reflect.ValueOf(err)
+	if isNil(ev) {
+		return false
+	}
+
+	var wrapped reflect.Value
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = reflect.ValueOf(u.Unwrap())
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); next != nil {
+			wrapped = reflect.ValueOf(&next).Elem()
+		}
+	}
+	var method string
+	var frames []string
+	if !s.scanning {
+		method, frames = stackTrace(err)
+	}
+
+	n := s.node(ev)
+	n.Form, n.Width = FormError, len("Error()")
+	if wrapped.IsValid() {
+		n.Width = len("Unwrap()")
+	}
+	if frames != nil {
+		n.Width = max(n.Width, len(method+"()"))
+	}
+	s.r.BeginValue(n)
+
+	s.r.StructField("Error()", true)
+	msg := ""
+	if !s.scanning {
+		msg = err.Error()
+	}
+	mv := reflect.ValueOf(msg)
+	s.r.Scalar(s.node(mv), s.scalarText(mv))
+
+	if wrapped.IsValid() {
+		s.r.StructField("Unwrap()", true)
+		s.printValue(wrapped, depth+1)
+	}
+	if frames != nil {
+		s.r.StructField(method+"()", true)
+		s.printValue(reflect.ValueOf(frames), depth+1)
+	}
+
+	s.r.EndValue(n)
+	return true
+}
+
+// stackTrace returns the stack trace attached to err through one of the
+// stackMethods, formatted one frame per line, and the name of that method.
+func stackTrace(err error) (string, []string) {
+	ev := reflect.ValueOf(err)
+	for _, name := range stackMethods {
+		m := ev.MethodByName(name)
+		if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+			continue
+		}
+		if frames := formatFrames(m.Call(nil)[0]); frames != nil {
+			return name, frames
+		}
+	}
+	return "", nil
+}
+
+// formatFrames formats a stack trace given as *runtime.Frames, as a slice of
+// runtime.Frame, or as a slice of program counters such as runtime.Callers
+// returns. It returns nil for anything else.
+func formatFrames(v reflect.Value) []string {
+	var frames []runtime.Frame
+	switch {
+	case v.Type() == framesPtrType:
+		if v.IsNil() {
+			return nil
+		}
+		frames = collectFrames(v.Interface().(*runtime.Frames))
+	case v.Kind() == reflect.Slice && v.Type().Elem() == frameType:
+		for i := range v.Len() {
+			frames = append(frames, v.Index(i).Interface().(runtime.Frame))
+		}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uintptr:
+		pcs := make([]uintptr, v.Len())
+		for i := range pcs {
+			pcs[i] = uintptr(v.Index(i).Uint())
+		}
+		frames = collectFrames(runtime.CallersFrames(pcs))
+	default:
+		return nil
+	}
+
+	lines := make([]string, 0, len(frames))
+	for _, f := range frames {
+		lines = append(lines, fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line))
+	}
+	return lines
+}
+
+// collectFrames drains the frames iterator.
+func collectFrames(it *runtime.Frames) []runtime.Frame {
+	var frames []runtime.Frame
+	for {
+		f, more := it.Next()
+		if f.PC != 0 {
+			frames = append(frames, f)
+		}
+		if !more {
+			return frames
+		}
+	}
+}
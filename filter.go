@@ -0,0 +1,158 @@
+package godump
+
+import (
+	"path"
+	"reflect"
+	"strings"
+)
+
+// fieldPattern selects struct fields, either by dotted path such as
+// "User.Address.City" or, when it contains no dot, by field name at any depth.
+// Each segment is a glob as understood by path.Match.
+type fieldPattern struct {
+	segments []string
+}
+
+// parseFieldPatterns parses the patterns given to WithIncludeFields and WithExcludeFields.
+func parseFieldPatterns(patterns []string) []fieldPattern {
+	ps := make([]fieldPattern, 0, len(patterns))
+	for _, p := range patterns {
+		ps = append(ps, fieldPattern{segments: strings.Split(p, ".")})
+	}
+	return ps
+}
+
+// WithIncludeFields restricts struct fields to those matching one of the
+// patterns. A pattern containing dots is matched against the dotted path of a
+// field, starting with the name of the outermost struct type, as in
+// "User.Address.City"; any other pattern is matched against field names at any
+// depth. Patterns are globs, so "User.*.City" and "*ID" are valid. Fields
+// leading to a matching field are shown, as is everything inside a matching field.
+func WithIncludeFields(patterns ...string) Option {
+	return func(d *Dumper) {
+		d.includeFields = append(d.includeFields, parseFieldPatterns(patterns)...)
+	}
+}
+
+// WithExcludeFields hides struct fields matching one of the patterns, which
+// are interpreted as for WithIncludeFields. Exclusions take precedence over
+// inclusions.
+func WithExcludeFields(patterns ...string) Option {
+	return func(d *Dumper) {
+		d.excludeFields = append(d.excludeFields, parseFieldPatterns(patterns)...)
+	}
+}
+
+// byName reports whether the pattern matches field names rather than paths.
+func (p fieldPattern) byName() bool {
+	return len(p.segments) == 1
+}
+
+// matches reports whether the pattern selects the field at the dotted path.
+func (p fieldPattern) matches(fieldPath []string) bool {
+	if p.byName() {
+		return globMatch(p.segments[0], fieldPath[len(fieldPath)-1])
+	}
+	return len(fieldPath) == len(p.segments) && p.matchesPrefix(fieldPath)
+}
+
+// leadsTo reports whether a field matching the pattern may lie inside the
+// field at the dotted path, whose type is t.
+func (p fieldPattern) leadsTo(fieldPath []string, t reflect.Type) bool {
+	if p.byName() {
+		return typeHasField(t, p.segments[0], map[reflect.Type]bool{})
+	}
+	return len(fieldPath) < len(p.segments) && p.matchesPrefix(fieldPath)
+}
+
+// matchesPrefix reports whether the first segments of the pattern match the path.
+func (p fieldPattern) matchesPrefix(fieldPath []string) bool {
+	for i, name := range fieldPath {
+		if !globMatch(p.segments[i], name) {
+			return false
+		}
+	}
+	return true
+}
+
+// globMatch reports whether name matches the glob pattern. Malformed patterns match nothing.
+func globMatch(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// typeHasField reports whether values of type t may contain a struct field
+// whose name matches the glob. Interfaces may hold anything, so they always do.
+func typeHasField(t reflect.Type, glob string, seen map[reflect.Type]bool) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		case reflect.Interface:
+			return true
+		case reflect.Struct:
+			if seen[t] {
+				return false
+			}
+			seen[t] = true
+			for _, f := range cachedFields(t) {
+				if globMatch(glob, f.field.Name) || typeHasField(f.field.Type, glob, seen) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// dumpTag holds the options of a `dump:"..."` struct tag.
+type dumpTag struct {
+	skip      bool // dump:"-" hides the field
+	omitEmpty bool // dump:"omitempty" hides the field when it holds its zero value
+	redact    bool // dump:"redact" replaces the value by the redaction mask
+}
+
+// parseDumpTag parses the dump tag of a struct field.
+func parseDumpTag(tag reflect.StructTag) dumpTag {
+	var dt dumpTag
+	for _, opt := range strings.Split(tag.Get("dump"), ",") {
+		switch strings.TrimSpace(opt) {
+		case "-":
+			dt.skip = true
+		case "omitempty":
+			dt.omitEmpty = true
+		case "redact":
+			dt.redact = true
+		}
+	}
+	return dt
+}
+
+// filterField reports whether the field at the end of fieldPath, holding v,
+// is dumped, and whether an include pattern selects it along with everything
+// inside it.
+func (s *dumpState) filterField(field reflect.StructField, tag dumpTag, v reflect.Value, fieldPath []string) (show, included bool) {
+	if tag.skip || tag.omitEmpty && v.IsZero() {
+		return false, false
+	}
+	for _, p := range s.excludeFields {
+		if p.matches(fieldPath) {
+			return false, false
+		}
+	}
+	if len(s.includeFields) == 0 || s.included {
+		return true, s.included
+	}
+	for _, p := range s.includeFields {
+		if p.matches(fieldPath) {
+			return true, true
+		}
+	}
+	for _, p := range s.includeFields {
+		if p.leadsTo(fieldPath, field.Type) {
+			return true, false
+		}
+	}
+	return false, false
+}
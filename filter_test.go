@@ -0,0 +1,122 @@
+package godump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type filterAddress struct {
+	Street string
+	City   string
+}
+
+type filterUser struct {
+	ID       int
+	Name     string
+	Password string `dump:"-"`
+	Nickname string `dump:"omitempty"`
+	Address  filterAddress
+	Friends  []filterUser
+	Extra    any
+}
+
+func TestWithExcludeFields_ByName(t *testing.T) {
+	out := New(WithColor(false), WithExcludeFields("Name", "*ID")).DumpStr(filterUser{ID: 1, Name: "Alice"})
+
+	assert.NotContains(t, out, "+ID")
+	assert.NotContains(t, out, "+Name")
+	assert.Contains(t, out, "+Address")
+}
+
+func TestWithExcludeFields_ByPath(t *testing.T) {
+	u := filterUser{
+		Address: filterAddress{City: "Paris"},
+		Friends: []filterUser{{Address: filterAddress{City: "Rome"}}},
+	}
+	out := New(WithColor(false), WithExcludeFields("filterUser.Address.City")).DumpStr(u)
+
+	assert.NotContains(t, out, "Paris")
+	assert.Contains(t, out, "+Street")
+	assert.Contains(t, out, "Rome", "only the top-level path is excluded")
+}
+
+func TestWithIncludeFields_ByPath(t *testing.T) {
+	u := filterUser{ID: 7, Name: "Alice", Address: filterAddress{Street: "Main St", City: "Paris"}}
+	out := New(WithColor(false), WithIncludeFields("filterUser.Address.City")).DumpStr(u)
+
+	assert.Contains(t, out, "+Address")
+	assert.Contains(t, out, `"Paris"`)
+	assert.NotContains(t, out, "Main St")
+	assert.NotContains(t, out, "Alice")
+	assert.NotContains(t, out, "+ID")
+}
+
+func TestWithIncludeFields_GlobPath(t *testing.T) {
+	u := filterUser{Address: filterAddress{Street: "Main St", City: "Paris"}}
+	out := New(WithColor(false), WithIncludeFields("*.Address.*")).DumpStr(u)
+
+	assert.Contains(t, out, "Main St")
+	assert.Contains(t, out, "Paris")
+	assert.NotContains(t, out, "+Friends")
+}
+
+func TestWithIncludeFields_MatchedFieldShownWhole(t *testing.T) {
+	u := filterUser{Name: "Alice", Address: filterAddress{Street: "Main St", City: "Paris"}}
+	out := New(WithColor(false), WithIncludeFields("filterUser.Address")).DumpStr(u)
+
+	assert.Contains(t, out, "Main St")
+	assert.Contains(t, out, "Paris")
+	assert.NotContains(t, out, "Alice")
+}
+
+func TestWithIncludeFields_ByNameRevealsParents(t *testing.T) {
+	u := filterUser{
+		Name:    "Alice",
+		Address: filterAddress{Street: "Main St", City: "Paris"},
+		Friends: []filterUser{{Name: "Bob", Address: filterAddress{City: "Rome"}}},
+	}
+	out := New(WithColor(false), WithIncludeFields("City")).DumpStr(u)
+
+	assert.Contains(t, out, "Paris")
+	assert.Contains(t, out, "Rome")
+	assert.Contains(t, out, "+Friends")
+	assert.NotContains(t, out, "Alice")
+	assert.NotContains(t, out, "Bob")
+	assert.NotContains(t, out, "Main St")
+}
+
+func TestWithIncludeFields_ExcludeWins(t *testing.T) {
+	u := filterUser{Address: filterAddress{Street: "Main St", City: "Paris"}}
+	out := New(WithColor(false), WithIncludeFields("filterUser.Address"), WithExcludeFields("City")).DumpStr(u)
+
+	assert.Contains(t, out, "Main St")
+	assert.NotContains(t, out, "Paris")
+}
+
+func TestDumpTag_Skip(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(filterUser{Password: "hunter2"})
+
+	assert.NotContains(t, out, "Password")
+	assert.NotContains(t, out, "hunter2")
+}
+
+func TestDumpTag_OmitEmpty(t *testing.T) {
+	d := New(WithColor(false))
+
+	assert.NotContains(t, d.DumpStr(filterUser{}), "Nickname")
+	assert.Contains(t, d.DumpStr(filterUser{Nickname: "Al"}), "+Nickname")
+}
+
+func TestFieldFilters_JSONTree(t *testing.T) {
+	out := New(WithExcludeFields("Name")).DumpJSONTree(filterUser{Name: "Alice"})
+
+	assert.NotContains(t, out, "Alice")
+	assert.Contains(t, out, `"name": "Address"`)
+}
+
+func TestParseDumpTag(t *testing.T) {
+	assert.Equal(t, dumpTag{skip: true}, parseDumpTag(`dump:"-"`))
+	assert.Equal(t, dumpTag{omitEmpty: true}, parseDumpTag(`json:"x" dump:"omitempty"`))
+	assert.Equal(t, dumpTag{}, parseDumpTag(`json:"-"`))
+}
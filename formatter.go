@@ -0,0 +1,135 @@
+package godump
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// typeFormatter is how values of a registered type are dumped: either through
+// a proxy value dumped in their place, or directly by a render function.
+type typeFormatter struct {
+	proxy  func(v reflect.Value) any
+	render func(v reflect.Value, r Renderer)
+}
+
+// formatters holds the formatters registered with RegisterFormatter and
+// RegisterTypeRenderer. Concrete types are looked up by type; interface types
+// are tried in registration order against the types implementing them.
+var formatters struct {
+	sync.RWMutex
+	byType     map[reflect.Type]typeFormatter
+	interfaces []reflect.Type
+}
+
+// RegisterFormatter makes every Dumper dump values of type T as the proxy
+// value returned by fn, which is then dumped normally. If T is an interface
+// type, fn applies to all types implementing it. Registering a type again
+// replaces its formatter.
+//
+//	godump.RegisterFormatter(func(m Money) any { return m.String() })
+func RegisterFormatter[T any](fn func(T) any) {
+	typ := reflect.TypeFor[T]()
+	register(typ, typeFormatter{proxy: func(v reflect.Value) any {
+		return fn(v.Interface().(T))
+	}})
+}
+
+// RegisterTypeRenderer makes every Dumper dump values of type typ by calling
+// fn, which writes the value to r as renderer events, typically a single
+// Scalar or a BeginValue/EndValue pair around fields or entries. If typ is an
+// interface type, fn applies to all types implementing it. Registering a type
+// again replaces its renderer.
+func RegisterTypeRenderer(typ reflect.Type, fn func(v reflect.Value, r Renderer)) {
+	register(typ, typeFormatter{render: fn})
+}
+
+// register records f as the formatter of typ.
+func register(typ reflect.Type, f typeFormatter) {
+	formatters.Lock()
+	defer formatters.Unlock()
+	if formatters.byType == nil {
+		formatters.byType = map[reflect.Type]typeFormatter{}
+	}
+	if _, ok := formatters.byType[typ]; !ok && typ.Kind() == reflect.Interface {
+		formatters.interfaces = append(formatters.interfaces, typ)
+	}
+	formatters.byType[typ] = f
+}
+
+// lookupFormatter returns the formatter registered for typ or for the first
+// registered interface it implements.
+func lookupFormatter(typ reflect.Type) (typeFormatter, bool) {
+	formatters.RLock()
+	defer formatters.RUnlock()
+	if f, ok := formatters.byType[typ]; ok {
+		return f, true
+	}
+	if typ.Kind() == reflect.Interface {
+		return typeFormatter{}, false
+	}
+	for _, iface := range formatters.interfaces {
+		if typ.Implements(iface) {
+			return formatters.byType[iface], true
+		}
+	}
+	return typeFormatter{}, false
+}
+
+// printCustom renders v through the formatter registered for its type and
+// reports whether there was one. Nil values are left to the default nil
+// rendering, and a proxy of the type it stands in for is dumped normally.
+// The scanning pass does not call formatters, so each runs once per value
+// and dump, and back-references are only found among the values it renders.
+func (s *dumpState) printCustom(v reflect.Value, depth int) bool {
+	if !v.IsValid() || isNil(v) || slices.Contains(s.proxied, v.Type()) {
+		return false
+	}
+	f, ok := lookupFormatter(v.Type())
+	if !ok {
+		return false
+	}
+	val := forceExported(v)
+	if !val.CanInterface() {
+		return false
+	}
+	if s.scanning {
+		return true
+	}
+
+	if f.render != nil {
+		r := s.r
+		if s.pendingID > 0 {
+			r = &idRenderer{Renderer: s.r, id: s.pendingID}
+			s.pendingID = 0
+		}
+		f.render(val, r)
+		return true
+	}
+
+	s.proxied = append(s.proxied, v.Type())
+	s.printValue(reflect.ValueOf(f.proxy(val)), depth)
+	s.proxied = s.proxied[:len(s.proxied)-1]
+	return true
+}
+
+// idRenderer attaches the &N marker of a value rendered by a registered
+// renderer to the first node it renders.
+type idRenderer struct {
+	Renderer
+	id int
+}
+
+func (r *idRenderer) BeginValue(n Node) {
+	if r.id > 0 {
+		n.ID, r.id = r.id, 0
+	}
+	r.Renderer.BeginValue(n)
+}
+
+func (r *idRenderer) Scalar(n Node, text string) {
+	if r.id > 0 {
+		n.ID, r.id = r.id, 0
+	}
+	r.Renderer.Scalar(n, text)
+}
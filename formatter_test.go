@@ -0,0 +1,136 @@
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type money struct {
+	cents    int64
+	currency string
+}
+
+type uuid [4]byte
+
+type shape interface{ Area() float64 }
+
+type square struct{ side float64 }
+
+func (s square) Area() float64 { return s.side * s.side }
+
+type selfProxy struct{ N int }
+
+type sharedLabel struct{ text string }
+
+type counted struct{ n int }
+
+type linkedEntry struct {
+	Name   string
+	Parent *linkedEntry
+}
+
+var countedCalls atomic.Int64
+
+func init() {
+	RegisterFormatter(func(m money) any {
+		return fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency)
+	})
+	RegisterFormatter(func(s shape) any {
+		return map[string]float64{"area": s.Area()}
+	})
+	RegisterFormatter(func(p selfProxy) any {
+		return selfProxy{N: p.N * 2}
+	})
+	RegisterFormatter(func(c counted) any {
+		countedCalls.Add(1)
+		runtime.GC() // let the previous proxy be collected and its address reused
+		return map[string]int{"n": c.n}
+	})
+	RegisterFormatter(func(e *linkedEntry) any {
+		return map[string]any{"name": e.Name, "parent": e.Parent}
+	})
+	RegisterTypeRenderer(reflect.TypeFor[uuid](), func(v reflect.Value, r Renderer) {
+		u := v.Interface().(uuid)
+		r.Scalar(Node{Type: v.Type(), Kind: v.Kind(), Form: FormFormatted}, fmt.Sprintf("%x-%x", u[:2], u[2:]))
+	})
+	RegisterTypeRenderer(reflect.TypeFor[sharedLabel](), func(v reflect.Value, r Renderer) {
+		r.Scalar(Node{Type: v.Type(), Kind: v.Kind(), Form: FormFormatted}, v.Field(0).String())
+	})
+}
+
+func TestFormatter_Proxy(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(money{cents: 1250, currency: "EUR"})
+
+	assert.Contains(t, out, `"12.50 EUR"`)
+	assert.NotContains(t, out, "cents")
+}
+
+func TestFormatter_TemporaryProxiesAreNotBackReferences(t *testing.T) {
+	items := make([]counted, 50)
+	for i := range items {
+		items[i].n = i
+	}
+	countedCalls.Store(0)
+	out := New(WithColor(false)).DumpStr(items)
+
+	assert.NotContains(t, out, "↩︎")
+	assert.Contains(t, out, `"n" => 49`)
+	assert.EqualValues(t, len(items), countedCalls.Load(), "each proxy is computed once per dump")
+}
+
+func TestFormatter_CycleThroughProxyHasNoDanglingReference(t *testing.T) {
+	e := &linkedEntry{Name: "root"}
+	e.Parent = e
+
+	out := New(WithColor(false)).DumpStr(e)
+	assertReferencesResolve(t, out)
+	assert.Contains(t, out, "+Parent => ... (already dumped)")
+}
+
+func TestFormatter_UnexportedField(t *testing.T) {
+	type order struct {
+		total money
+	}
+	out := New(WithColor(false)).DumpStr(order{total: money{cents: 5, currency: "USD"}})
+
+	assert.Contains(t, out, `-total => "0.05 USD"`)
+}
+
+func TestFormatter_Interface(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(square{side: 3})
+
+	assert.Contains(t, out, `"area" => 9`)
+}
+
+func TestFormatter_ProxyOfSameType(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(selfProxy{N: 1})
+
+	assert.Contains(t, out, "+N => 2")
+}
+
+func TestTypeRenderer(t *testing.T) {
+	out := New(WithColor(false)).DumpStr([]uuid{{0xde, 0xad, 0xbe, 0xef}})
+
+	assert.Contains(t, out, "0 => dead-beef #godump.uuid")
+}
+
+func TestTypeRenderer_KeepsReferenceIDs(t *testing.T) {
+	l := &sharedLabel{text: "hi"}
+	out := New(WithColor(false)).DumpStr([]*sharedLabel{l, l})
+
+	assert.Contains(t, out, "0 => &1 hi #godump.sharedLabel")
+	assert.Contains(t, out, "1 => ↩︎ &1")
+}
+
+func TestFormatter_JSONTree(t *testing.T) {
+	out := DumpJSONTree(money{cents: 100, currency: "GBP"})
+
+	require.Contains(t, out, `"type": "string"`)
+	assert.Contains(t, out, `"value": "1.00 GBP"`)
+}
@@ -1,152 +1,74 @@
 package godump
 
 import (
-	"encoding/json"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
-	"text/tabwriter"
+	"sync"
 	"unicode/utf8"
 	"unsafe"
 )
 
-const (
-	colorReset   = "\033[0m"
-	colorGray    = "\033[90m"
-	colorYellow  = "\033[33m"
-	colorLime    = "\033[38;5;113m"
-	colorCyan    = "\033[38;5;38m"
-	colorNote    = "\033[38;5;38m"
-	colorRef     = "\033[38;5;247m"
-	colorMeta    = "\033[38;5;170m"
-	colorDefault = "\033[38;5;208m"
-	indentWidth  = 2
-)
+const indentWidth = 2
 
 var exitFunc = os.Exit
 
-var (
-	maxDepth     = 15
-	maxItems     = 100
-	maxStringLen = 100000
-	enableColor  = detectColor()
-	nextRefID    = 1
-	referenceMap = map[uintptr]int{}
-)
-
-// Colorizer is a function type that takes a color code and a string, returning the colorized string.
-type Colorizer func(code, str string) string
-
-// colorize is the default colorizer function.
-var colorize Colorizer = ansiColorize // default
-
-// ansiColorize colorizes the string using ANSI escape codes.
-func ansiColorize(code, str string) string {
-	if !enableColor {
-		return str
-	}
-	return code + str + colorReset
-}
-
-// htmlColorMap maps color codes to HTML colors.
-var htmlColorMap = map[string]string{
-	colorGray:    "#999",
-	colorYellow:  "#ffb400",
-	colorLime:    "#80ff80",
-	colorNote:    "#40c0ff",
-	colorRef:     "#aaa",
-	colorMeta:    "#d087d0",
-	colorDefault: "#ff7f00",
-}
-
-// htmlColorize colorizes the string using HTML span tags.
-func htmlColorize(code, str string) string {
-	return fmt.Sprintf(`<span style="color:%s">%s</span>`, htmlColorMap[code], str)
-}
+// defaultDumper backs the package-level functions.
+var defaultDumper = New()
 
 // Dump prints the values to stdout with colorized output.
 func Dump(vs ...any) {
-	printDumpHeader(os.Stdout, 3)
-	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	writeDump(tw, vs...)
-	tw.Flush()
+	defaultDumper.Dump(vs...)
 }
 
 // Fdump writes the formatted dump of values to the given io.Writer.
 func Fdump(w io.Writer, vs ...any) {
-	printDumpHeader(w, 3)
-	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
-	writeDump(tw, vs...)
-	tw.Flush()
+	defaultDumper.Fdump(w, vs...)
+}
+
+// Tap dumps v to stdout like Dump and returns it, so a value can be inspected
+// in the middle of an expression:
+//
+//	total := godump.Tap(price * qty)
+func Tap[T any](v T) T {
+	defaultDumper.Dump(v)
+	return v
+}
+
+// TapF dumps v to the given io.Writer like Fdump and returns it.
+func TapF[T any](w io.Writer, v T) T {
+	defaultDumper.Fdump(w, v)
+	return v
 }
 
 // DumpStr dumps the values as a string with colorized output.
 func DumpStr(vs ...any) string {
-	var sb strings.Builder
-	printDumpHeader(&sb, 3)
-	tw := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
-	writeDump(tw, vs...)
-	tw.Flush()
-	return sb.String()
+	return defaultDumper.DumpStr(vs...)
 }
 
-// DumpHTML dumps the values as HTML with colorized output.
+// DumpHTML dumps the values as a standalone, interactive HTML document.
 func DumpHTML(vs ...any) string {
-	prevColorize := ansiColorize
-	prevEnable := enableColor
-	defer func() {
-		colorize = prevColorize
-		enableColor = prevEnable
-	}()
-
-	// Enable HTML coloring
-	colorize = htmlColorize
-	enableColor = true
-
-	var sb strings.Builder
-	sb.WriteString(`<body style='background-color:black;'><pre style="background-color:black; color:white; padding:5px; border-radius: 5px"></body>` + "\n")
-
-	tw := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
-	printDumpHeader(&sb, 3)
-	writeDump(tw, vs...)
-	tw.Flush()
-
-	sb.WriteString("</pre>")
-	return sb.String()
+	return defaultDumper.DumpHTML(vs...)
 }
 
 // DumpJSON dumps the values as a pretty-printed JSON string.
 // If there is more than one value, they are dumped as a JSON array.
 // It returns an error string if marshalling fails.
 func DumpJSON(vs ...any) string {
-	if len(vs) == 0 {
-		return `{"error": "DumpJSON called with no arguments"}`
-	}
-
-	var data any = vs
-	if len(vs) == 1 {
-		data = vs[0]
-	}
-
-	b, err := json.MarshalIndent(data, "", strings.Repeat(" ", indentWidth))
-	if err != nil {
-		return fmt.Sprintf(`{"error": "%s"}`, err.Error())
-	}
-	return string(b)
+	return defaultDumper.DumpJSON(vs...)
 }
 
 // Dd is a debug function that prints the values and exits the program.
 func Dd(vs ...any) {
-	Dump(vs...)
-	exitFunc(1)
+	defaultDumper.Dd(vs...)
 }
 
-// printDumpHeader prints the header for the dump output, including the file and line number.
-func printDumpHeader(out io.Writer, skip int) {
+// printDumpHeader renders the header for the dump output, including the file and line number.
+func (d *Dumper) printDumpHeader(r Renderer, skip int) {
 	file, line := findFirstNonInternalFrame()
 	if file == "" {
 		return
@@ -159,8 +81,7 @@ func printDumpHeader(out io.Writer, skip int) {
 		}
 	}
 
-	header := fmt.Sprintf("<#dump // %s:%d", relPath, line)
-	fmt.Fprintln(out, colorize(colorGray, header))
+	r.Header(relPath, line)
 }
 
 // findFirstNonInternalFrame finds the first non-internal frame in the call stack.
@@ -181,256 +102,413 @@ func findFirstNonInternalFrame() (string, int) {
 	return "", 0
 }
 
-// formatByteSliceAsHexDump formats a byte slice as a hex dump with ASCII representation.
-func formatByteSliceAsHexDump(b []byte, indent int) string {
-	var sb strings.Builder
-
-	const lineLen = 16
-	const asciiStartCol = 50
-	const asciiMaxLen = 16
-
-	fieldIndent := strings.Repeat(" ", indent*indentWidth)
-	bodyIndent := fieldIndent
-
-	// Header
-	sb.WriteString(fmt.Sprintf("([]uint8) (len=%d cap=%d) {\n", len(b), cap(b)))
+// callerLocation returns the file and line number of the caller at the specified skip level.
+func callerLocation(skip int) (string, int) {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "", 0
+	}
+	return file, line
+}
 
-	for i := 0; i < len(b); i += lineLen {
-		end := min(i+lineLen, len(b))
-		line := b[i:end]
+// dumpState holds the state of a single dump call, so concurrent dumps never
+// share reference ids.
+type dumpState struct {
+	*Dumper
+	r              Renderer
+	guard          guardRenderer  // r, tracking the values left open by a panic
+	scanning       bool           // first pass: count visits without producing output
+	visits         map[refKey]int // visit counts gathered by the scanning pass
+	refs           map[refKey]int // values already printed, mapped to their id or 0
+	nextRefID      int
+	pendingID      int            // &N marker to attach to the next rendered node
+	pendingDecoded string         // format the next rendered node was decoded from
+	path           []string       // dotted path of the struct field being rendered
+	included       bool           // inside a field selected by an include pattern
+	inline         bool           // render the next struct or array on a single line
+	inKey          bool           // rendering a map key
+	proxied        []reflect.Type // types whose proxy value is being rendered
+	shown          []shownField   // fields of the structs being rendered, innermost last
+}
 
-		visibleLen := 0
+// statePool recycles dump states, so the maps and slices they grow are
+// reused by later dumps.
+var statePool = sync.Pool{
+	New: func() any {
+		return &dumpState{visits: map[refKey]int{}, refs: map[refKey]int{}}
+	},
+}
 
-		// Offset
-		offsetStr := fmt.Sprintf("%08x  ", i)
-		sb.WriteString(bodyIndent)
-		sb.WriteString(colorize(colorMeta, offsetStr))
-		visibleLen += len(offsetStr)
+// newDumpState returns a state for a single dump rendering to r. Call release
+// once the dump is done.
+func newDumpState(d *Dumper, r Renderer) *dumpState {
+	s := statePool.Get().(*dumpState)
+	s.Dumper = d
+	s.guard.Renderer = r
+	s.r = &s.guard
+	s.nextRefID = 1
+	return s
+}
 
-		// Hex bytes
-		for j := range lineLen {
-			var hexStr string
-			if j < len(line) {
-				hexStr = fmt.Sprintf("%02x ", line[j])
-			} else {
-				hexStr = "   "
-			}
-			if j == 7 {
-				hexStr += " "
-			}
-			sb.WriteString(colorize(colorCyan, hexStr))
-			visibleLen += len(hexStr)
-		}
+// release resets s and returns it to the pool.
+func (s *dumpState) release() {
+	clear(s.visits)
+	clear(s.refs)
+	clear(s.shown)
+	clear(s.proxied)
+	*s = dumpState{
+		visits:  s.visits,
+		refs:    s.refs,
+		guard:   guardRenderer{open: s.guard.open[:0]},
+		path:    s.path[:0],
+		proxied: s.proxied[:0],
+		shown:   s.shown[:0],
+	}
+	statePool.Put(s)
+}
 
-		// Padding before ASCII
-		padding := max(1, asciiStartCol-visibleLen)
-		sb.WriteString(strings.Repeat(" ", padding))
-
-		// ASCII section
-		sb.WriteString(colorize(colorGray, "| "))
-		asciiCount := 0
-		for _, c := range line {
-			ch := "."
-			if c >= 32 && c <= 126 {
-				ch = string(c)
-			}
-			sb.WriteString(colorize(colorLime, ch))
-			asciiCount++
-		}
-		if asciiCount < asciiMaxLen {
-			sb.WriteString(strings.Repeat(" ", asciiMaxLen-asciiCount))
-		}
-		sb.WriteString(colorize(colorGray, " |") + "\n")
+// writeDump renders the values, handling references and indentation.
+// The values are walked twice: a silent first pass finds the pointers, maps and
+// slices that are reached more than once, so their first occurrence can carry
+// the &N marker that later back-references point to.
+func (d *Dumper) writeDump(r Renderer, vs ...any) {
+	rvs := make([]reflect.Value, len(vs))
+	for i, v := range vs {
+		rvs[i] = makeAddressable(reflect.ValueOf(v))
 	}
 
-	// Closing
-	fieldIndent = fieldIndent[:len(fieldIndent)-indentWidth]
-	sb.WriteString(fieldIndent + "}")
-	return sb.String()
-}
+	scan := newDumpState(d, discardRenderer{})
+	scan.scanning = true
+	for _, rv := range rvs {
+		scan.printValue(rv, 0)
+	}
 
-// callerLocation returns the file and line number of the caller at the specified skip level.
-func callerLocation(skip int) (string, int) {
-	_, file, line, ok := runtime.Caller(skip)
-	if !ok {
-		return "", 0
+	s := newDumpState(d, r)
+	s.visits, scan.visits = scan.visits, s.visits
+	scan.release()
+	for _, rv := range rvs {
+		s.printValue(rv, 0)
 	}
-	return file, line
+	s.release()
 }
 
-// writeDump writes the values to the tabwriter, handling references and indentation.
-func writeDump(tw *tabwriter.Writer, vs ...any) {
-	referenceMap = map[uintptr]int{} // reset each time
-	visited := map[uintptr]bool{}
-	for _, v := range vs {
-		rv := reflect.ValueOf(v)
-		rv = makeAddressable(rv)
-		printValue(tw, rv, 0, visited)
-		fmt.Fprintln(tw)
+// node describes v for the renderer, attaching any pending &N marker.
+func (s *dumpState) node(v reflect.Value) Node {
+	n := Node{ID: s.pendingID, Decoded: s.pendingDecoded}
+	s.pendingID, s.pendingDecoded = 0, ""
+	if !v.IsValid() {
+		return n
 	}
+	n.Type = v.Type()
+	n.Kind = v.Kind()
+	switch n.Kind {
+	case reflect.String, reflect.Array, reflect.Map:
+		n.Len = v.Len()
+	case reflect.Slice:
+		n.Len, n.Cap = v.Len(), v.Cap()
+	}
+	return n
 }
 
-// printValue recursively prints the value with indentation and handles references.
-func printValue(tw *tabwriter.Writer, v reflect.Value, indent int, visited map[uintptr]bool) {
-	if indent > maxDepth {
-		fmt.Fprint(tw, colorize(colorGray, "... (max depth)"))
+// printValue recursively renders the value and handles references. A panic
+// while rendering it is rendered as a marker in its place.
+func (s *dumpState) printValue(v reflect.Value, depth int) {
+	defer s.recoverNode(v, s.mark())
+	if depth > s.maxDepth {
+		s.r.Truncated(TruncatedDepth)
 		return
 	}
 	if !v.IsValid() {
-		fmt.Fprint(tw, colorize(colorGray, "<invalid>"))
+		s.r.Scalar(s.node(v), "<invalid>")
 		return
 	}
 
-	if s := asStringer(v); s != "" {
-		fmt.Fprint(tw, s)
+	if s.scanning && !mayHoldRefs(v.Type()) {
 		return
 	}
-
-	switch v.Kind() {
-	case reflect.Chan:
-		if v.IsNil() {
-			fmt.Fprint(tw, colorize(colorGray, v.Type().String()+"(nil)"))
-		} else {
-			fmt.Fprintf(tw, "%s(%s)", colorize(colorGray, v.Type().String()), colorize(colorCyan, fmt.Sprintf("%#x", v.Pointer())))
-		}
+	if s.printFormatted(v, depth) {
 		return
 	}
 
 	if isNil(v) {
-		typeStr := v.Type().String()
-		fmt.Fprintf(tw, colorize(colorLime, typeStr)+colorize(colorGray, "(nil)"))
+		n := s.node(v)
+		n.Form = FormNil
+		s.r.Scalar(n, "")
 		return
 	}
 
-	if v.Kind() == reflect.Ptr && v.CanAddr() {
-		ptr := v.Pointer()
-		if id, ok := referenceMap[ptr]; ok {
-			fmt.Fprintf(tw, colorize(colorRef, "↩︎ &%d"), id)
-			return
-		} else {
-			referenceMap[ptr] = nextRefID
-			nextRefID++
-		}
+	if id, seen := s.trackRef(v); seen {
+		s.printReference(id)
+		return
+	} else if id > 0 {
+		s.pendingID = id
 	}
 
 	switch v.Kind() {
 	case reflect.Ptr, reflect.Interface:
-		printValue(tw, v.Elem(), indent, visited)
+		s.printValue(v.Elem(), depth)
 	case reflect.Struct:
-		t := v.Type()
-		fmt.Fprintf(tw, "%s ", colorize(colorGray, "#"+t.String()))
-		fmt.Fprintln(tw)
-		visibleFields := reflect.VisibleFields(t)
-		for _, field := range visibleFields {
-			fieldVal := v.FieldByIndex(field.Index)
-			symbol := "+"
-			if field.PkgPath != "" {
-				symbol = "-"
-				fieldVal = forceExported(fieldVal)
-			}
-			indentPrint(tw, indent+1, colorize(colorYellow, symbol)+field.Name)
-			fmt.Fprint(tw, "	=> ")
-			if s := asStringer(fieldVal); s != "" {
-				fmt.Fprint(tw, s)
-			} else {
-				printValue(tw, fieldVal, indent+1, visited)
-			}
-			fmt.Fprintln(tw)
+		n := s.node(v)
+		if s.inline {
+			n.Form, s.inline = FormInline, false
 		}
-		indentPrint(tw, indent, "")
-		fmt.Fprint(tw, "}")
-	case reflect.Complex64, reflect.Complex128:
-		fmt.Fprint(tw, colorize(colorCyan, fmt.Sprintf("%v", v.Complex())))
-	case reflect.UnsafePointer:
-		fmt.Fprint(tw, colorize(colorGray, fmt.Sprintf("unsafe.Pointer(%#x)", v.Pointer())))
+		fields := s.structFields(v)
+		n.Width = fieldWidth(fields)
+		s.r.BeginValue(n)
+		s.printFields(v, fields, depth)
+		s.releaseFields(fields)
+		s.r.EndValue(n)
 	case reflect.Map:
-		fmt.Fprintln(tw, "{")
+		if s.scanning && !mayHoldRefs(v.Type().Key()) && !mayHoldRefs(v.Type().Elem()) {
+			return
+		}
+		n := s.node(v)
+		s.r.BeginValue(n)
 		keys := v.MapKeys()
+		if s.sortMaps {
+			sortMapKeys(keys)
+		}
 		for i, key := range keys {
-			if i >= maxItems {
-				indentPrint(tw, indent+1, colorize(colorGray, "... (truncated)"))
+			if i >= s.maxItems {
+				s.r.Truncated(TruncatedItems)
 				break
 			}
-			keyStr := fmt.Sprintf("%v", key.Interface())
-			indentPrint(tw, indent+1, fmt.Sprintf(" %s => ", colorize(colorMeta, keyStr)))
-			printValue(tw, v.MapIndex(key), indent+1, visited)
-			fmt.Fprintln(tw)
+			s.r.MapKey()
+			s.inline, s.inKey = inlineKey(key), true
+			s.printValue(key, depth+1)
+			s.inline, s.inKey = false, false
+			s.r.MapValue()
+			if s.sensitiveKey(key) {
+				s.printRedacted(v.MapIndex(key))
+				continue
+			}
+			s.printValue(v.MapIndex(key), depth+1)
 		}
-		indentPrint(tw, indent, "")
-		fmt.Fprint(tw, "}")
+		s.r.EndValue(n)
 	case reflect.Slice, reflect.Array:
-		// []byte handling
+		if s.scanning && !mayHoldRefs(v.Type().Elem()) {
+			return
+		}
+		n := s.node(v)
+		// []byte and [N]byte handling; inline map keys stay plain arrays
 		if v.Type().Elem().Kind() == reflect.Uint8 {
-			if v.CanConvert(reflect.TypeOf([]byte{})) { // Check if it can be converted to []byte
-				if data, ok := v.Convert(reflect.TypeOf([]byte{})).Interface().([]byte); ok {
-					hexDump := formatByteSliceAsHexDump(data, indent+1)
-					fmt.Fprint(tw, colorize(colorLime, hexDump))
-					break
-				}
+			if v.Kind() == reflect.Slice && v.CanConvert(bytesType) {
+				s.printBytes(n, v.Convert(bytesType).Bytes())
+				return
+			}
+			if v.Kind() == reflect.Array && !s.inline {
+				s.printBytes(n, arrayBytes(v))
+				return
 			}
 		}
+		if s.inline {
+			n.Form, s.inline = FormInline, false
+		}
 
-		// Default rendering for other slices/arrays
-		fmt.Fprintln(tw, "[")
+		s.r.BeginValue(n)
 		for i := range v.Len() {
-			if i >= maxItems {
-				indentPrint(tw, indent+1, colorize(colorGray, "... (truncated)\n"))
+			if i >= s.maxItems {
+				s.r.Truncated(TruncatedItems)
 				break
 			}
-			indentPrint(tw, indent+1, fmt.Sprintf("%s => ", colorize(colorCyan, fmt.Sprintf("%d", i))))
-			printValue(tw, v.Index(i), indent+1, visited)
-			fmt.Fprintln(tw)
+			s.r.SliceIndex(i)
+			s.printValue(v.Index(i), depth+1)
 		}
-		indentPrint(tw, indent, "")
-		fmt.Fprint(tw, "]")
+		s.r.EndValue(n)
+	default:
+		s.r.Scalar(s.node(v), s.scalarText(v))
+	}
+}
+
+// arrayBytes copies the bytes of a byte array, which may be unaddressable
+// or of a named byte type, into a slice.
+func arrayBytes(v reflect.Value) []byte {
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	return b
+}
 
+// maxInlineItems is the largest number of fields or elements of a map key
+// rendered on a single line.
+const maxInlineItems = 6
+
+// inlineKey reports whether a map key is a struct or array of at most
+// maxInlineItems booleans, numbers and strings, so it fits on a single line.
+func inlineKey(v reflect.Value) bool {
+	for (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && !v.IsNil() {
+		v = v.Elem()
+	}
+	var items []reflect.Type
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range cachedFields(v.Type()) {
+			items = append(items, f.field.Type)
+		}
+	case reflect.Array:
+		for range v.Len() {
+			items = append(items, v.Type().Elem())
+		}
+	default:
+		return false
+	}
+	if len(items) > maxInlineItems {
+		return false
+	}
+	for _, t := range items {
+		switch t.Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// bytesType is the reflect.Type of []byte.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// scalarText returns the text form of a leaf value.
+func (s *dumpState) scalarText(v reflect.Value) string {
+	if s.scanning {
+		return ""
+	}
+	switch v.Kind() {
 	case reflect.String:
-		str := escapeControl(v.String())
-		if utf8.RuneCountInString(str) > maxStringLen {
+		str := escapeControl(s.redactString(v.String()))
+		if utf8.RuneCountInString(str) > s.maxStringLen {
 			runes := []rune(str)
-			str = string(runes[:maxStringLen]) + "…"
+			str = string(runes[:s.maxStringLen]) + "…"
 		}
-		fmt.Fprint(tw, colorize(colorYellow, `"`)+colorize(colorLime, str)+colorize(colorYellow, `"`))
+		return str
 	case reflect.Bool:
-		if v.Bool() {
-			fmt.Fprint(tw, colorize(colorYellow, "true"))
-		} else {
-			fmt.Fprint(tw, colorize(colorGray, "false"))
-		}
+		return strconv.FormatBool(v.Bool())
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		fmt.Fprint(tw, colorize(colorCyan, fmt.Sprint(v.Int())))
+		return strconv.FormatInt(v.Int(), 10)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		fmt.Fprint(tw, colorize(colorCyan, fmt.Sprint(v.Uint())))
+		return strconv.FormatUint(v.Uint(), 10)
 	case reflect.Float32, reflect.Float64:
-		fmt.Fprint(tw, colorize(colorCyan, fmt.Sprintf("%f", v.Float())))
+		return strconv.FormatFloat(v.Float(), 'f', 6, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, 128)
+	case reflect.Chan, reflect.UnsafePointer:
+		return "0x" + strconv.FormatUint(uint64(v.Pointer()), 16)
 	case reflect.Func:
-		fmt.Fprint(tw, colorize(colorGray, "func(...) {...}"))
+		return "func(...) {...}"
 	default:
-		// unreachable; all reflect.Kind cases are handled
+		// unreachable; all other kinds are handled by printValue
+		return ""
 	}
 }
 
-// asStringer checks if the value implements fmt.Stringer and returns its string representation.
-func asStringer(v reflect.Value) string {
-	val := v
-	if !val.CanInterface() {
-		val = forceExported(val)
+// shownField is a struct field that passes the field filters.
+type shownField struct {
+	*fieldInfo
+	value    reflect.Value
+	included bool // selected by an include pattern
+}
+
+// structFields returns the fields of the struct v that pass the field filters.
+// The fields are appended to s.shown, which releaseFields truncates once they
+// are rendered, so nested structs reuse the same backing array.
+func (s *dumpState) structFields(v reflect.Value) []shownField {
+	root := len(s.path) == 0
+	if root {
+		s.path = append(s.path, v.Type().Name())
 	}
-	if val.CanInterface() {
-		if s, ok := val.Interface().(fmt.Stringer); ok {
-			rv := reflect.ValueOf(s)
-			if rv.Kind() == reflect.Ptr && rv.IsNil() {
-				return colorize(colorGray, val.Type().String()+"(nil)")
-			}
-			return colorize(colorLime, s.String()) + colorize(colorGray, " #"+val.Type().String())
+	start := len(s.shown)
+	infos := cachedFields(v.Type())
+	for i := range infos {
+		info := &infos[i]
+		fieldVal := v.FieldByIndex(info.field.Index)
+		if !info.exported {
+			fieldVal = forceExported(fieldVal)
 		}
+		s.path = append(s.path, info.field.Name)
+		if show, included := s.filterField(info.field, info.tag, fieldVal, s.path); show {
+			s.shown = append(s.shown, shownField{fieldInfo: info, value: fieldVal, included: included})
+		}
+		s.path = s.path[:len(s.path)-1]
+	}
+	if root {
+		s.path = s.path[:0]
+	}
+	return s.shown[start:]
+}
+
+// releaseFields drops the fields returned by the innermost structFields call
+// once they are rendered.
+func (s *dumpState) releaseFields(fields []shownField) {
+	clear(s.shown[len(s.shown)-len(fields):])
+	s.shown = s.shown[:len(s.shown)-len(fields)]
+}
+
+// fieldWidth returns the length of the longest name among fields.
+func fieldWidth(fields []shownField) int {
+	width := 0
+	for _, f := range fields {
+		width = max(width, f.width)
 	}
-	return ""
+	return width
 }
 
-// indentPrint prints indented text to the tabwriter.
-func indentPrint(tw *tabwriter.Writer, indent int, text string) {
-	fmt.Fprint(tw, strings.Repeat(" ", indent*indentWidth)+text)
+// printFields renders the given fields of the struct v.
+func (s *dumpState) printFields(v reflect.Value, fields []shownField, depth int) {
+	root := len(s.path) == 0
+	if root {
+		s.path = append(s.path, v.Type().Name())
+	}
+	for _, f := range fields {
+		s.path = append(s.path, f.field.Name)
+		wasIncluded := s.included
+		s.included = f.included
+		s.r.StructField(f.field.Name, f.exported)
+		if f.tag.redact || s.sensitiveNormalized(f.norm) {
+			s.printRedacted(f.value)
+		} else {
+			s.printFieldValue(f.value, depth+1)
+		}
+		s.included = wasIncluded
+		s.path = s.path[:len(s.path)-1]
+	}
+	if root {
+		s.path = s.path[:0]
+	}
+}
+
+// printFieldValue renders the value of a struct field. Past the maximum
+// depth, a field is still rendered if a formatter renders it as a leaf.
+func (s *dumpState) printFieldValue(v reflect.Value, depth int) {
+	if depth <= s.maxDepth {
+		s.printValue(v, depth)
+		return
+	}
+	defer s.recoverNode(v, s.mark())
+	if !v.IsValid() || !s.printLeaf(v, depth) {
+		s.r.Truncated(TruncatedDepth)
+	}
+}
+
+// printLeaf renders v through a formatter that does not dump any other value,
+// such as a registered renderer, the time formatter or a String method used
+// in place of the value, and reports whether it did.
+func (s *dumpState) printLeaf(v reflect.Value, depth int) bool {
+	if f, ok := lookupFormatter(v.Type()); ok && f.render != nil && s.printCustom(v, depth) {
+		return true
+	}
+	return s.printTime(v) || (s.stringerMode != StringerBoth && s.printStringer(v, depth))
+}
+
+// printFormatted renders v through a registered formatter, its DumpValue
+// method, a dedicated formatter, the document it holds or its String method
+// and reports whether it did.
+func (s *dumpState) printFormatted(v reflect.Value, depth int) bool {
+	return s.printCustom(v, depth) || s.printDumpable(v, depth) || s.printTime(v) ||
+		s.printError(v, depth) || s.printEmbedded(v, depth) || s.printStringer(v, depth)
 }
 
 // forceExported returns a value that is guaranteed to be exported, even if it is unexported.
@@ -485,14 +563,3 @@ var replacer = strings.NewReplacer(
 func escapeControl(s string) string {
 	return replacer.Replace(s)
 }
-
-// detectColor checks environment variables to determine if color output should be enabled.
-func detectColor() bool {
-	if os.Getenv("NO_COLOR") != "" {
-		return false
-	}
-	if os.Getenv("FORCE_COLOR") != "" {
-		return true
-	}
-	return true
-}
@@ -3,11 +3,13 @@ package godump
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"text/tabwriter"
 	"time"
@@ -82,8 +84,60 @@ func TestMapOutput(t *testing.T) {
 	m := map[string]int{"a": 1, "b": 2}
 	out := stripANSI(DumpStr(m))
 
-	assert.Contains(t, out, "a => 1")
-	assert.Contains(t, out, "b => 2")
+	assert.Contains(t, out, `"a" => 1`)
+	assert.Contains(t, out, `"b" => 2`)
+}
+
+func TestMapKeys_StringsQuoted(t *testing.T) {
+	out := stripANSI(DumpStr(map[any]int{"1": 1, 1: 2}))
+
+	assert.Contains(t, out, `"1" => 1`)
+	assert.Contains(t, out, "   1 => 2")
+}
+
+func TestMapKeys_SmallStructInline(t *testing.T) {
+	type point struct {
+		X, y int
+	}
+	out := stripANSI(DumpStr(map[point]string{{X: 1, y: 2}: "a"}))
+
+	assert.Contains(t, out, `#godump.point {+X: 1, -y: 2} => "a"`)
+}
+
+func TestMapKeys_SmallArrayInline(t *testing.T) {
+	out := stripANSI(DumpStr(map[[2]string]bool{{"a", "b"}: true}))
+
+	assert.Contains(t, out, `["a", "b"] => true`)
+}
+
+func TestMapKeys_LargeStructMultiline(t *testing.T) {
+	type key struct {
+		Name  string
+		Inner struct{ ID int }
+	}
+	out := stripANSI(DumpStr(map[key]int{{Name: "k"}: 7}))
+
+	assert.Contains(t, out, "#godump.key \n")
+	assert.Contains(t, out, `+Name  => "k"`)
+	assert.Contains(t, out, "} => 7")
+}
+
+func TestMapKeys_PointerIdentity(t *testing.T) {
+	type node struct{ ID int }
+	n := &node{ID: 1}
+	out := stripANSI(DumpStr(map[*node]*node{n: n}))
+
+	assert.Contains(t, out, "&1 #godump.node {+ID: 1} => ↩︎ &1")
+}
+
+func TestMapKeys_UnexportedKeyType(t *testing.T) {
+	type key struct{ id int }
+	type holder struct {
+		m map[key]int
+	}
+	out := stripANSI(DumpStr(holder{m: map[key]int{{id: 1}: 2}}))
+
+	assert.Contains(t, out, "#godump.key {-id: 1} => 2")
 }
 
 func TestSliceOutput(t *testing.T) {
@@ -175,14 +229,15 @@ func TestDetectColorVariants(t *testing.T) {
 
 func TestPrintDumpHeaderFallback(t *testing.T) {
 	// Intentionally skip enough frames so findFirstNonInternalFrame returns empty
-	printDumpHeader(os.Stdout, 100)
+	defaultDumper.printDumpHeader(NewANSIRenderer(os.Stdout, ThemeDark, Color256), 100)
 }
 
-func TestHtmlColorizeUnknown(t *testing.T) {
-	// Color not in htmlColorMap
-	out := htmlColorize("\033[999m", "test")
-	assert.Contains(t, out, `<span style="color:`)
-	assert.Contains(t, out, "test")
+func TestHtmlStyles_MalformedColor(t *testing.T) {
+	// Malformed colors leave the role unstyled
+	open, close := Theme{String: "#zzz", Number: "#40c0ff"}.htmlStyles()
+	assert.Empty(t, open[roleString])
+	assert.Empty(t, close[roleString])
+	assert.Equal(t, `<span style="color:#40c0ff">`, open[roleNumber])
 }
 
 // package-level type + method
@@ -197,7 +252,7 @@ func TestUnreadableFallback(t *testing.T) {
 	var ch chan int // nil typed value, not interface
 	rv := reflect.ValueOf(ch)
 
-	printValue(tw, rv, 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(rv, 0)
 	tw.Flush()
 
 	output := stripANSI(b.String())
@@ -217,7 +272,7 @@ func TestUnreadableFieldFallback(t *testing.T) {
 	var sb strings.Builder
 	tw := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
 
-	printValue(tw, v, 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(v, 0)
 	tw.Flush()
 
 	out := stripANSI(sb.String())
@@ -270,7 +325,7 @@ func TestDefaultFallback_Unreadable(t *testing.T) {
 
 	var buf strings.Builder
 	tw := tabwriter.NewWriter(&buf, 0, 0, 1, ' ', 0)
-	printValue(tw, v, 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(v, 0)
 	tw.Flush()
 
 	assert.Contains(t, buf.String(), "<invalid>")
@@ -281,7 +336,7 @@ func TestPrintValue_Uintptr(t *testing.T) {
 	val := uintptr(12345)
 	var buf strings.Builder
 	tw := tabwriter.NewWriter(&buf, 0, 0, 1, ' ', 0)
-	printValue(tw, reflect.ValueOf(val), 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(reflect.ValueOf(val), 0)
 	tw.Flush()
 
 	assert.Contains(t, buf.String(), "12345")
@@ -293,7 +348,7 @@ func TestPrintValue_UnsafePointer(t *testing.T) {
 	up := unsafe.Pointer(&i)
 	var buf strings.Builder
 	tw := tabwriter.NewWriter(&buf, 0, 0, 1, ' ', 0)
-	printValue(tw, reflect.ValueOf(up), 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(reflect.ValueOf(up), 0)
 	tw.Flush()
 
 	assert.Contains(t, buf.String(), "unsafe.Pointer")
@@ -303,7 +358,7 @@ func TestPrintValue_Func(t *testing.T) {
 	fn := func() {}
 	var buf strings.Builder
 	tw := tabwriter.NewWriter(&buf, 0, 0, 1, ' ', 0)
-	printValue(tw, reflect.ValueOf(fn), 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(reflect.ValueOf(fn), 0)
 	tw.Flush()
 
 	assert.Contains(t, buf.String(), "func(...) {...}")
@@ -381,22 +436,16 @@ func TestNilChan(t *testing.T) {
 }
 
 func TestTruncatedSlice(t *testing.T) {
-	orig := maxItems
-	maxItems = 5
-	defer func() { maxItems = orig }()
 	slice := make([]int, 10)
-	out := DumpStr(slice)
+	out := New(WithMaxItems(5)).DumpStr(slice)
 	if !strings.Contains(out, "... (truncated)") {
 		t.Error("Expected slice to be truncated")
 	}
 }
 
 func TestTruncatedString(t *testing.T) {
-	orig := maxStringLen
-	maxStringLen = 10
-	defer func() { maxStringLen = orig }()
 	s := strings.Repeat("x", 50)
-	out := DumpStr(s)
+	out := New(WithMaxStringLen(10)).DumpStr(s)
 	if !strings.Contains(out, "…") {
 		t.Error("Expected long string to be truncated")
 	}
@@ -413,7 +462,7 @@ func TestDefaultBranchFallback(t *testing.T) {
 	var v reflect.Value // zero reflect.Value
 	var sb strings.Builder
 	tw := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
-	printValue(tw, v, 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(v, 0)
 	tw.Flush()
 	if !strings.Contains(sb.String(), "<invalid>") {
 		t.Error("Expected default fallback for invalid reflect.Value")
@@ -602,7 +651,7 @@ func TestTheKitchenSink(t *testing.T) {
 	assert.Contains(t, out, "+ArrayStrings")
 	assert.Contains(t, out, `"foo"`)
 	assert.Contains(t, out, "+MapValues")
-	assert.Contains(t, out, "a => 1")
+	assert.Contains(t, out, `"a" => 1`)
 	assert.Contains(t, out, "+Nested")
 	assert.Contains(t, out, "+ID") // from nested
 	assert.Contains(t, out, "+Notes")
@@ -617,12 +666,10 @@ func TestTheKitchenSink(t *testing.T) {
 }
 
 func TestAnsiColorize_Disabled(t *testing.T) {
-	orig := enableColor
-	enableColor = false
-	defer func() { enableColor = orig }()
-
-	out := ansiColorize(colorYellow, "test")
-	assert.Equal(t, "test", out)
+	var sb strings.Builder
+	r := NewPlainRenderer(&sb).(*textRenderer)
+	r.write(rolePunctuation, "test")
+	assert.Equal(t, "test", sb.String())
 }
 
 func TestForceExportedFallback(t *testing.T) {
@@ -633,12 +680,11 @@ func TestForceExportedFallback(t *testing.T) {
 }
 
 func TestAnsiColorize_DisabledBranch(t *testing.T) {
-	orig := enableColor
-	enableColor = false
-	defer func() { enableColor = orig }()
-
-	out := ansiColorize(colorLime, "xyz")
-	assert.Equal(t, "xyz", out)
+	// Roles without a color in the theme are written unstyled
+	var sb strings.Builder
+	r := NewANSIRenderer(&sb, Theme{}, Color256).(*textRenderer)
+	r.write(roleString, "xyz")
+	assert.Equal(t, "xyz", sb.String())
 }
 
 func TestFindFirstNonInternalFrame_FallbackBranch(t *testing.T) {
@@ -673,7 +719,7 @@ func TestPrintDumpHeader_SkipWhenNoFrame(t *testing.T) {
 	}
 
 	var b strings.Builder
-	printDumpHeader(&b, 3)
+	defaultDumper.printDumpHeader(NewANSIRenderer(&b, ThemeDark, Color256), 3)
 	assert.Equal(t, "", b.String()) // nothing should be written
 }
 
@@ -704,7 +750,7 @@ func TestPrintValue_ChanNilBranch_Hardforce(t *testing.T) {
 	assert.True(t, v.IsNil())
 	assert.Equal(t, reflect.Chan, v.Kind())
 
-	printValue(tw, v, 0, map[uintptr]bool{})
+	newDumpState(defaultDumper, NewANSIRenderer(tw, ThemeDark, Color256)).printValue(v, 0)
 	tw.Flush()
 
 	out := stripANSI(buf.String())
@@ -726,9 +772,10 @@ func TestAsStringer_ForceExported(t *testing.T) {
 	v := reflect.ValueOf(h).Elem().FieldByName("secret") // now v.CanAddr() is true, but v.CanInterface() is false
 
 	assert.False(t, v.CanInterface(), "field must not be interfaceable")
-	str := asStringer(v)
+	str, ok := asStringer(v)
 
-	assert.Contains(t, str, "👻 hidden stringer")
+	require.True(t, ok)
+	assert.Contains(t, str.String(), "👻 hidden stringer")
 }
 
 func TestForceExported_Interfaceable(t *testing.T) {
@@ -883,3 +930,96 @@ func TestDumpJSON(t *testing.T) {
 		assert.JSONEq(t, "[1, 2]", jsonStr)
 	})
 }
+
+func TestConcurrentDumpsDoNotShareReferences(t *testing.T) {
+	type Node struct {
+		Next *Node
+	}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := &Node{}
+			n.Next = n
+			for range 20 {
+				out := stripANSI(DumpStr(n))
+				assert.Contains(t, out, "↩︎ &1")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentDumpRace(t *testing.T) {
+	type Leaf struct {
+		Name string
+		Tags map[string]int
+	}
+	type Tree struct {
+		Left, Right *Leaf
+		Shared      *Leaf
+	}
+	shared := &Leaf{Name: "shared", Tags: map[string]int{"a": 1}}
+	tree := Tree{Left: shared, Right: &Leaf{Name: "right"}, Shared: shared}
+
+	d := New(WithWriter(io.Discard))
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			d.Dump(tree)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = d.DumpStr(tree)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReferenceIDsRestartPerDump(t *testing.T) {
+	type Node struct {
+		Next *Node
+	}
+	a := &Node{}
+	a.Next = a
+	b := &Node{}
+	b.Next = b
+
+	first := stripANSI(DumpStr(a))
+	second := stripANSI(DumpStr(b))
+	assert.Contains(t, first, "↩︎ &1")
+	assert.Contains(t, second, "↩︎ &1")
+	assert.NotContains(t, second, "&2")
+}
+
+func TestTapF_ReturnsValue(t *testing.T) {
+	var buf strings.Builder
+
+	total := TapF(&buf, 6*7) + 1
+
+	assert.Equal(t, 43, total)
+	out := stripANSI(buf.String())
+	assert.Contains(t, out, "<#dump //")
+	assert.Contains(t, out, "42")
+}
+
+func TestTap_WritesToStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	user := Tap(map[string]int{"age": 30})
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, 30, user["age"])
+	assert.Contains(t, stripANSI(string(out)), `"age" => 30`)
+}
@@ -0,0 +1,300 @@
+package godump
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// htmlDumpSeq numbers HTML dumps so the anchors of several dumps embedded in
+// one page never clash.
+var htmlDumpSeq atomic.Uint64
+
+// htmlRenderer renders values as interactive HTML: the layout of the text
+// output, with collapsible structs, maps and slices, back-references linking to
+// the value they point to, and a copy-path button on every entry. It relies on
+// the CSS and JavaScript emitted by DumpHTML and DumpHTMLFragment.
+type htmlRenderer struct {
+	textRenderer
+	prefix    string   // anchor prefix unique to this dump
+	paths     []string // paths of the open values, innermost last
+	cur       string   // path of the value rendered next
+	entryOpen []bool   // whether an entry span is open at each depth
+	keys      []string // path segments of the map keys being rendered
+	capturing bool     // the next node is the outermost node of a map key
+}
+
+// NewHTMLRenderer returns a Renderer producing interactive HTML colorized with
+// the colors of the theme. Its output is meant to be placed in the container
+// produced by DumpHTMLFragment, which supplies the styles and scripts.
+func NewHTMLRenderer(w io.Writer, theme Theme) Renderer {
+	r := &htmlRenderer{
+		textRenderer: textRenderer{w: w, escape: html.EscapeString},
+		prefix:       "gd" + strconv.FormatUint(htmlDumpSeq.Add(1), 10),
+		entryOpen:    []bool{false},
+	}
+	r.open, r.close = theme.htmlStyles()
+	return r
+}
+
+// DumpHTMLFragment dumps the values as an embeddable HTML fragment, carrying its
+// own inline styles and scripts.
+func DumpHTMLFragment(vs ...any) string {
+	return defaultDumper.DumpHTMLFragment(vs...)
+}
+
+// DumpHTML dumps the values as a standalone HTML document with collapsible
+// nodes, clickable back-references, a search box and copy-path buttons.
+func (d *Dumper) DumpHTML(vs ...any) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>godump</title>\n</head>\n")
+	fmt.Fprintf(&sb, "<body style=\"margin:0; padding:8px; background-color:%s;\">\n", d.theme.Background)
+	sb.WriteString(d.DumpHTMLFragment(vs...))
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// DumpHTMLFragment dumps the values as an embeddable HTML fragment, carrying its
+// own inline styles and scripts.
+func (d *Dumper) DumpHTMLFragment(vs ...any) string {
+	hd := *d
+	hd.newRenderer = func(w io.Writer) Renderer {
+		return NewHTMLRenderer(w, d.theme)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<div class=\"godump\">\n")
+	fmt.Fprintf(&sb, "<style>%s</style>\n", htmlCSS(d.theme))
+	sb.WriteString(`<div class="gd-toolbar"><input class="gd-search" type="search" placeholder="Search…">` +
+		`<button type="button" data-gd-action="expand">Expand all</button>` +
+		`<button type="button" data-gd-action="collapse">Collapse all</button></div>` + "\n")
+	sb.WriteString("<pre>")
+	hd.Fdump(&sb, vs...)
+	sb.WriteString("</pre>\n")
+	fmt.Fprintf(&sb, "<script>%s</script>\n", htmlJS)
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// htmlCSS returns the styles of the HTML output for the theme.
+func htmlCSS(theme Theme) string {
+	return fmt.Sprintf(`
+.godump{background-color:%s;color:%s;border-radius:5px;font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:13px}
+.godump .gd-toolbar{display:flex;gap:6px;padding:6px 6px 0}
+.godump .gd-toolbar input{flex:1;font:inherit}
+.godump .gd-toolbar button{font:inherit;cursor:pointer}
+.godump pre{margin:0;padding:5px;font:inherit;overflow:auto}
+.godump .gd-toggle{cursor:pointer}
+.godump .gd-collapsed>.gd-children{display:none}
+.godump .gd-collapsed>.gd-toggle::after{content:" …"}
+.godump .gd-copy{visibility:hidden;cursor:pointer;opacity:.6;margin-left:4px;user-select:none}
+.godump .gd-entry:hover>.gd-copy{visibility:visible}
+.godump .gd-match>span:not(.gd-node){background-color:rgba(255,220,0,.3)}
+.godump a.gd-ref{text-decoration:none}
+.godump .gd-target{outline:1px solid currentColor}
+`, theme.Background, theme.Text)
+}
+
+// htmlJS wires up the interactive behavior of every godump container on the
+// page that has not been initialized yet.
+const htmlJS = `
+(function(){
+document.querySelectorAll('.godump:not([data-gd-ready])').forEach(function(root){
+root.setAttribute('data-gd-ready','');
+function expand(el){for(var p=el.parentNode;p&&p!==root;p=p.parentNode){if(p.classList&&p.classList.contains('gd-node'))p.classList.remove('gd-collapsed');}}
+function ownText(el){var s='';el.childNodes.forEach(function(c){if(c.nodeType===3){s+=c.textContent;}else if(!c.classList.contains('gd-node')&&!c.classList.contains('gd-copy')){s+=c.textContent;}});return s;}
+root.addEventListener('click',function(e){
+var t=e.target.closest('.gd-toggle,.gd-copy,.gd-ref,[data-gd-action]');
+if(!t||!root.contains(t))return;
+if(t.classList.contains('gd-toggle')){t.parentNode.classList.toggle('gd-collapsed');}
+else if(t.classList.contains('gd-copy')){if(navigator.clipboard){navigator.clipboard.writeText(t.getAttribute('data-path'));}t.textContent='✓';setTimeout(function(){t.textContent='⧉';},800);}
+else if(t.classList.contains('gd-ref')){var target=document.getElementById(t.getAttribute('href').slice(1));if(target){e.preventDefault();expand(target);target.scrollIntoView({block:'center'});target.classList.add('gd-target');setTimeout(function(){target.classList.remove('gd-target');},1500);}}
+else{var collapse=t.getAttribute('data-gd-action')==='collapse';root.querySelectorAll('.gd-node').forEach(function(n){n.classList.toggle('gd-collapsed',collapse);});}
+});
+var search=root.querySelector('.gd-search');
+search.addEventListener('input',function(){
+var q=search.value.toLowerCase();
+root.querySelectorAll('.gd-entry').forEach(function(en){var hit=q!==''&&ownText(en).toLowerCase().indexOf(q)>=0;en.classList.toggle('gd-match',hit);if(hit)expand(en);});
+});
+});
+})();
+`
+
+// rootPath returns the path of a top-level value: its type name, or $ for unnamed types.
+func rootPath(n Node) string {
+	if n.Type != nil && n.Type.Name() != "" {
+		return n.Type.Name()
+	}
+	return "$"
+}
+
+// anchor writes the &N marker of a value other values point back to, as a
+// link target.
+func (r *htmlRenderer) anchor(n Node) {
+	if n.ID > 0 {
+		fmt.Fprintf(r.w, `<span id="%s-ref-%d">`, r.prefix, n.ID)
+		r.write(roleReference, fmt.Sprintf("&%d ", n.ID))
+		io.WriteString(r.w, "</span>")
+	}
+}
+
+// startEntry opens the span of a struct field, map entry or slice element
+// whose path is the enclosing value's path followed by suffix.
+func (r *htmlRenderer) startEntry(suffix string) {
+	r.closeEntry()
+	r.cur = r.paths[len(r.paths)-1] + suffix
+	io.WriteString(r.w, `<span class="gd-entry">`)
+	r.newline(r.depth)
+	r.entryOpen[r.depth] = true
+}
+
+// copyButton writes the copy-path button of the current entry.
+func (r *htmlRenderer) copyButton() {
+	fmt.Fprintf(r.w, `<span class="gd-copy" data-path="%s" title="Copy path">⧉</span>`, html.EscapeString(r.cur))
+}
+
+// closeEntry closes the entry span open at the current depth, if any.
+func (r *htmlRenderer) closeEntry() {
+	if r.entryOpen[r.depth] {
+		io.WriteString(r.w, "</span>")
+		r.entryOpen[r.depth] = false
+	}
+}
+
+func (r *htmlRenderer) Header(file string, line int) {
+	io.WriteString(r.w, `<span class="gd-header">`)
+	r.write(roleMeta, fmt.Sprintf("<#dump // %s:%d", file, line))
+	io.WriteString(r.w, "</span>\n")
+}
+
+// captureKey records the path segment of the map key being rendered from its
+// outermost node.
+func (r *htmlRenderer) captureKey(segment string) {
+	if r.capturing {
+		r.keys[len(r.keys)-1] = segment
+		r.capturing = false
+	}
+}
+
+func (r *htmlRenderer) BeginValue(n Node) {
+	if r.depth == 0 {
+		r.cur = rootPath(n)
+	}
+	r.captureKey(typeName(n.Type) + "{…}")
+	if n.Form == FormInline || r.inline > 0 {
+		r.textRenderer.BeginValue(n)
+		return
+	}
+	io.WriteString(r.w, `<span class="gd-node">`)
+	r.anchor(n)
+	io.WriteString(r.w, `<span class="gd-toggle">`)
+	n.ID = 0
+	r.textRenderer.BeginValue(n)
+	io.WriteString(r.w, `</span><span class="gd-children">`)
+	r.paths = append(r.paths, r.cur)
+	r.entryOpen = append(r.entryOpen, false)
+}
+
+func (r *htmlRenderer) EndValue(n Node) {
+	if n.Form == FormInline || r.inline > 0 {
+		r.textRenderer.EndValue(n)
+		return
+	}
+	r.closeEntry()
+	r.entryOpen = r.entryOpen[:len(r.entryOpen)-1]
+	r.paths = r.paths[:len(r.paths)-1]
+	r.widths = r.widths[:len(r.widths)-1]
+	r.depth--
+	r.newline(r.depth)
+	io.WriteString(r.w, "</span>")
+	r.write(roleNone, closer(n))
+	io.WriteString(r.w, "</span>")
+	r.done()
+}
+
+func (r *htmlRenderer) StructField(name string, exported bool) {
+	if r.inline > 0 {
+		r.textRenderer.StructField(name, exported)
+		return
+	}
+	r.startEntry("." + name)
+	symbol := "+"
+	if !exported {
+		symbol = "-"
+	}
+	r.write(rolePunctuation, symbol)
+	r.write(roleField, name)
+	r.copyButton()
+	r.alignField(name)
+	r.write(roleNone, " => ")
+}
+
+func (r *htmlRenderer) MapKey() {
+	r.startEntry("")
+	r.write(roleNone, " ")
+	r.inKey++
+	r.keys = append(r.keys, "")
+	r.capturing = true
+}
+
+func (r *htmlRenderer) MapValue() {
+	r.inKey--
+	key := r.keys[len(r.keys)-1]
+	r.keys = r.keys[:len(r.keys)-1]
+	r.capturing = false
+	r.cur = r.paths[len(r.paths)-1] + "[" + key + "]"
+	r.copyButton()
+	r.write(roleNone, " => ")
+}
+
+func (r *htmlRenderer) SliceIndex(index int) {
+	if r.inline > 0 {
+		r.textRenderer.SliceIndex(index)
+		return
+	}
+	r.startEntry("[" + strconv.Itoa(index) + "]")
+	r.write(roleNumber, strconv.Itoa(index))
+	r.copyButton()
+	r.write(roleNone, " => ")
+}
+
+func (r *htmlRenderer) Scalar(n Node, text string) {
+	if r.depth == 0 {
+		r.cur = rootPath(n)
+	}
+	switch {
+	case n.Form == FormNil:
+		r.captureKey("nil")
+	case n.Kind == reflect.String && n.Form == FormValue:
+		r.captureKey(`"` + text + `"`)
+	default:
+		r.captureKey(text)
+	}
+	r.anchor(n)
+	n.ID = 0
+	r.textRenderer.Scalar(n, text)
+}
+
+func (r *htmlRenderer) Reference(id int) {
+	r.captureKey(fmt.Sprintf("&%d", id))
+	fmt.Fprintf(r.w, `<a class="gd-ref" href="#%s-ref-%d">`, r.prefix, id)
+	r.write(roleReference, fmt.Sprintf("↩︎ &%d", id))
+	io.WriteString(r.w, "</a>")
+	r.done()
+}
+
+func (r *htmlRenderer) Truncated(t Truncation) {
+	if t != TruncatedItems {
+		r.textRenderer.Truncated(t)
+		return
+	}
+	r.closeEntry()
+	io.WriteString(r.w, `<span class="gd-entry">`)
+	r.newline(r.depth)
+	r.write(roleMeta, "... (truncated)")
+	io.WriteString(r.w, "</span>")
+}
@@ -0,0 +1,97 @@
+package godump
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpHTML_StandaloneDocument(t *testing.T) {
+	html := DumpHTML(42)
+
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.Contains(t, html, `<meta charset="utf-8">`)
+	assert.Contains(t, html, "<style>")
+	assert.Contains(t, html, "<script>")
+	assert.True(t, strings.HasSuffix(html, "</html>\n"))
+	assert.NotContains(t, html, "src=")
+	assert.NotContains(t, html, "<link")
+}
+
+func TestDumpHTMLFragment_Embeddable(t *testing.T) {
+	html := DumpHTMLFragment(42)
+
+	assert.True(t, strings.HasPrefix(html, `<div class="godump">`))
+	assert.NotContains(t, html, "<html")
+	assert.NotContains(t, html, "<body")
+	assert.Contains(t, html, `class="gd-search"`)
+	assert.Contains(t, html, `data-gd-action="expand"`)
+	assert.Contains(t, html, `data-gd-action="collapse"`)
+}
+
+func TestDumpHTML_CollapsibleNodes(t *testing.T) {
+	html := DumpHTMLFragment(map[string][]int{"a": {1}})
+
+	assert.Equal(t, 2, strings.Count(html, `<span class="gd-node">`))
+	assert.Equal(t, strings.Count(html, `<span class="gd-node">`), strings.Count(html, `<span class="gd-children">`))
+	assert.Contains(t, html, `<span class="gd-toggle">{</span>`)
+	assert.Contains(t, html, `<span class="gd-toggle">[</span>`)
+}
+
+func TestDumpHTML_CopyPaths(t *testing.T) {
+	type Address struct{ City string }
+	type User struct {
+		Name    string
+		Address Address
+		Tags    []string
+		Meta    map[string]int
+	}
+	html := DumpHTMLFragment(User{Name: "Alice", Tags: []string{"x"}, Meta: map[string]int{"k": 1}})
+
+	assert.Contains(t, html, `data-path="User.Name"`)
+	assert.Contains(t, html, `data-path="User.Address.City"`)
+	assert.Contains(t, html, `data-path="User.Tags[0]"`)
+	assert.Contains(t, html, `data-path="User.Meta[&#34;k&#34;]"`)
+}
+
+func TestDumpHTML_ReferencesLinkToAnchors(t *testing.T) {
+	type Node struct {
+		Next *Node
+	}
+	n := &Node{}
+	n.Next = n
+	html := DumpHTMLFragment(n)
+
+	anchor := regexp.MustCompile(`<span id="(gd\d+-ref-1)">`).FindStringSubmatch(html)
+	require.NotNil(t, anchor)
+	assert.Contains(t, html, `<a class="gd-ref" href="#`+anchor[1]+`">`)
+}
+
+func TestDumpHTML_AnchorsUniquePerDump(t *testing.T) {
+	m := map[string]any{}
+	m["self"] = m
+	id := regexp.MustCompile(`id="(gd\d+)-ref-1"`)
+
+	first := id.FindStringSubmatch(DumpHTMLFragment(m))
+	second := id.FindStringSubmatch(DumpHTMLFragment(m))
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	assert.NotEqual(t, first[1], second[1])
+}
+
+func TestDumpHTML_EscapesPaths(t *testing.T) {
+	html := DumpHTMLFragment(map[string]int{`"><b>`: 1})
+
+	assert.NotContains(t, html, `"><b>`)
+	assert.Contains(t, html, `data-path="$[&#34;&#34;&gt;&lt;b&gt;&#34;]"`)
+}
+
+func TestDumpHTML_KeepsThemeColors(t *testing.T) {
+	html := New(WithTheme(ThemeSolarized)).DumpHTMLFragment(true)
+
+	assert.Contains(t, html, `<span style="color:`+string(ThemeSolarized.Bool)+`">true</span>`)
+	assert.Contains(t, html, "background-color:"+string(ThemeSolarized.Background))
+}
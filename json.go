@@ -0,0 +1,259 @@
+package godump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// jsonNode is a node of the JSON tree output. It mirrors what the text output
+// shows for a value: its type, kind, visibility, value and children.
+type jsonNode struct {
+	Name       string      `json:"name,omitempty"`
+	Visibility string      `json:"visibility,omitempty"`
+	Key        any         `json:"key,omitempty"`
+	Index      *int        `json:"index,omitempty"`
+	Type       string      `json:"type,omitempty"`
+	Kind       string      `json:"kind,omitempty"`
+	Form       string      `json:"form,omitempty"`
+	ID         int         `json:"id,omitempty"`
+	Ref        int         `json:"ref,omitempty"`
+	Len        *int        `json:"len,omitempty"`
+	Cap        *int        `json:"cap,omitempty"`
+	Value      any         `json:"value,omitempty"`
+	Content    string      `json:"content,omitempty"`
+	Decoded    string      `json:"decoded,omitempty"`
+	Truncated  string      `json:"truncated,omitempty"`
+	Fields     []*jsonNode `json:"fields,omitempty"`
+	Entries    []*jsonNode `json:"entries,omitempty"`
+	Items      []*jsonNode `json:"items,omitempty"`
+
+	entry bool // the node is a map entry
+}
+
+// jsonRenderer builds a tree of jsonNodes from renderer events and hands each
+// completed top-level value to emit.
+type jsonRenderer struct {
+	emit    func(*jsonNode)
+	stack   []*jsonNode
+	pending *jsonNode // child position announced by StructField, MapValue or SliceIndex
+}
+
+// NewJSONRenderer returns a Renderer writing each dumped value to w as a JSON
+// document on its own line. The documents describe the same tree as the text
+// output, including unexported fields, reference ids and truncation markers.
+func NewJSONRenderer(w io.Writer) Renderer {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonRenderer{emit: func(n *jsonNode) { enc.Encode(n) }}
+}
+
+// DumpJSONTree dumps the values as a pretty-printed JSON tree that mirrors the
+// text output. If there is more than one value, they are dumped as a JSON array.
+func DumpJSONTree(vs ...any) string {
+	return defaultDumper.DumpJSONTree(vs...)
+}
+
+// DumpJSONTree dumps the values as a pretty-printed JSON tree that mirrors the
+// text output. If there is more than one value, they are dumped as a JSON array.
+func (d *Dumper) DumpJSONTree(vs ...any) string {
+	var roots []*jsonNode
+	d.writeDump(&jsonRenderer{emit: func(n *jsonNode) { roots = append(roots, n) }}, vs...)
+
+	var data any = roots
+	if len(roots) == 1 {
+		data = roots[0]
+	}
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", strings.Repeat(" ", indentWidth))
+	if err := enc.Encode(data); err != nil {
+		return fmt.Sprintf(`{"error": %q}`, err.Error())
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// child returns the node for the next value, placed where the last
+// StructField, MapValue or SliceIndex announced it.
+func (r *jsonRenderer) child() *jsonNode {
+	n := r.pending
+	r.pending = nil
+	if n == nil {
+		n = &jsonNode{}
+	}
+	return n
+}
+
+// describe fills in the type information of the node.
+func (r *jsonRenderer) describe(jn *jsonNode, n Node) {
+	if n.Type != nil {
+		jn.Type = typeName(n.Type)
+	}
+	if n.Kind != reflect.Invalid {
+		jn.Kind = n.Kind.String()
+	}
+	switch n.Form {
+	case FormValue, FormInline:
+	case FormNil:
+		jn.Form = "nil"
+	case FormStringer:
+		jn.Form = "stringer"
+	case FormHexdump:
+		jn.Form = "hexdump"
+	case FormRedacted:
+		jn.Form = "redacted"
+	case FormFormatted:
+		jn.Form = "formatted"
+	case FormError:
+		jn.Form = "error"
+	case FormPanic:
+		jn.Form = "panic"
+	}
+	jn.ID, jn.Content, jn.Decoded = n.ID, n.Content, n.Decoded
+	switch n.Kind {
+	case reflect.String, reflect.Array, reflect.Map:
+		if n.Form == FormValue || n.Form == FormInline || n.Form == FormHexdump {
+			jn.Len = &n.Len
+		}
+	case reflect.Slice:
+		if n.Form != FormNil {
+			jn.Len, jn.Cap = &n.Len, &n.Cap
+		}
+	}
+}
+
+// finish emits a completed node if it is a top-level value.
+func (r *jsonRenderer) finish(n *jsonNode) {
+	if len(r.stack) == 0 {
+		r.emit(n)
+	}
+}
+
+// attach adds n to the children of the innermost open value.
+func (r *jsonRenderer) attach(n *jsonNode) {
+	if len(r.stack) == 0 {
+		return
+	}
+	parent := r.stack[len(r.stack)-1]
+	switch {
+	case n.Visibility != "":
+		parent.Fields = append(parent.Fields, n)
+	case n.entry:
+		parent.Entries = append(parent.Entries, n)
+	default:
+		parent.Items = append(parent.Items, n)
+	}
+}
+
+func (r *jsonRenderer) Header(string, int) {}
+
+func (r *jsonRenderer) BeginValue(n Node) {
+	jn := r.child()
+	r.describe(jn, n)
+	r.attach(jn)
+	r.stack = append(r.stack, jn)
+}
+
+func (r *jsonRenderer) EndValue(Node) {
+	jn := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	r.finish(jn)
+}
+
+func (r *jsonRenderer) StructField(name string, exported bool) {
+	visibility := "exported"
+	if !exported {
+		visibility = "unexported"
+	}
+	r.pending = &jsonNode{Name: name, Visibility: visibility}
+}
+
+// MapKey collects the key of the entry in a placeholder node, which MapValue
+// replaces by the entry.
+func (r *jsonRenderer) MapKey() {
+	r.stack = append(r.stack, &jsonNode{})
+}
+
+// MapValue announces the entry, keyed by the key's value if it is a plain
+// scalar and by the key's node otherwise.
+func (r *jsonRenderer) MapValue() {
+	holder := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+
+	var key any
+	if len(holder.Items) > 0 {
+		k := holder.Items[0]
+		key = k
+		if k.Form == "" && k.Value != nil && k.ID == 0 {
+			key = k.Value
+		}
+	}
+	r.pending = &jsonNode{Key: key, entry: true}
+}
+
+func (r *jsonRenderer) SliceIndex(index int) {
+	r.pending = &jsonNode{Index: &index}
+}
+
+func (r *jsonRenderer) HexRow(_ int, hex, _ string) {
+	jn := r.stack[len(r.stack)-1]
+	value, _ := jn.Value.(string)
+	jn.Value = value + strings.Join(strings.Fields(hex), "")
+}
+
+func (r *jsonRenderer) Scalar(n Node, text string) {
+	jn := r.child()
+	r.describe(jn, n)
+	switch {
+	case n.Form == FormNil:
+	case n.Form == FormStringer, n.Form == FormRedacted, n.Form == FormFormatted, n.Form == FormPanic:
+		jn.Value = text
+	default:
+		switch n.Kind {
+		case reflect.Bool:
+			jn.Value = text == "true"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			jn.Value = jsonNumber(text)
+		default:
+			jn.Value = text
+		}
+	}
+	r.attach(jn)
+	r.finish(jn)
+}
+
+func (r *jsonRenderer) Reference(id int) {
+	jn := r.child()
+	jn.Ref = id
+	r.attach(jn)
+	r.finish(jn)
+}
+
+func (r *jsonRenderer) Truncated(t Truncation) {
+	switch t {
+	case TruncatedItems:
+		r.stack[len(r.stack)-1].Truncated = "max items"
+	default:
+		jn := r.child()
+		jn.Truncated = "max depth"
+		if t == TruncatedRepeat {
+			jn.Truncated = "already dumped"
+		}
+		r.attach(jn)
+		r.finish(jn)
+	}
+}
+
+// jsonNumber returns text as a JSON number, or as a string for values such as
+// NaN and Inf that JSON cannot represent.
+func jsonNumber(text string) any {
+	if !json.Valid([]byte(text)) {
+		return text
+	}
+	return json.Number(text)
+}
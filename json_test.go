@@ -0,0 +1,129 @@
+package godump
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpJSONTree_Struct(t *testing.T) {
+	type User struct {
+		Name   string
+		age    int
+		Tags   []string
+		Scores map[string]float64
+	}
+	out := DumpJSONTree(User{Name: "Alice", age: 30, Tags: []string{"a"}, Scores: map[string]float64{"x": 1.5}})
+
+	expected := `{
+  "type": "godump.User",
+  "kind": "struct",
+  "fields": [
+    {"name": "Name", "visibility": "exported", "type": "string", "kind": "string", "len": 5, "value": "Alice"},
+    {"name": "age", "visibility": "unexported", "type": "int", "kind": "int", "value": 30},
+    {"name": "Tags", "visibility": "exported", "type": "[]string", "kind": "slice", "len": 1, "cap": 1, "items": [
+      {"index": 0, "type": "string", "kind": "string", "len": 1, "value": "a"}
+    ]},
+    {"name": "Scores", "visibility": "exported", "type": "map[string]float64", "kind": "map", "len": 1, "entries": [
+      {"key": "x", "type": "float64", "kind": "float64", "value": 1.5}
+    ]}
+  ]
+}`
+	assert.JSONEq(t, expected, out)
+}
+
+func TestDumpJSONTree_Cycle(t *testing.T) {
+	type Node struct {
+		Next *Node
+	}
+	n := &Node{}
+	n.Next = n
+
+	var tree map[string]any
+	require.NoError(t, json.Unmarshal([]byte(DumpJSONTree(n)), &tree))
+
+	assert.EqualValues(t, 1, tree["id"])
+	field := tree["fields"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Next", field["name"])
+	assert.EqualValues(t, 1, field["ref"])
+}
+
+func TestDumpJSONTree_UnmarshallableKinds(t *testing.T) {
+	type Handlers struct {
+		Ch   chan int
+		Fn   func()
+		Nil  *int
+		Data []byte
+	}
+	out := DumpJSONTree(Handlers{Ch: make(chan int), Fn: func() {}, Data: []byte("hi")})
+
+	var tree map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &tree))
+	fields := tree["fields"].([]any)
+
+	ch := fields[0].(map[string]any)
+	assert.Equal(t, "chan", ch["kind"])
+	assert.True(t, strings.HasPrefix(ch["value"].(string), "0x"))
+
+	fn := fields[1].(map[string]any)
+	assert.Equal(t, "func(...) {...}", fn["value"])
+
+	nilPtr := fields[2].(map[string]any)
+	assert.Equal(t, "nil", nilPtr["form"])
+	assert.Equal(t, "*int", nilPtr["type"])
+	assert.NotContains(t, nilPtr, "value")
+
+	data := fields[3].(map[string]any)
+	assert.Equal(t, "hexdump", data["form"])
+	assert.Equal(t, "6869", data["value"])
+}
+
+func TestDumpJSONTree_Truncation(t *testing.T) {
+	d := New(WithMaxItems(2), WithMaxDepth(1))
+	out := d.DumpJSONTree(map[string]any{"list": []int{1, 2, 3}, "deep": map[string]any{"x": []int{1}}})
+
+	assert.Contains(t, out, `"truncated": "max items"`)
+	assert.Contains(t, out, `"truncated": "max depth"`)
+}
+
+func TestDumpJSONTree_MultipleValuesAndSpecialFloats(t *testing.T) {
+	out := DumpJSONTree(1, "two", math.NaN())
+
+	var tree []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &tree))
+	require.Len(t, tree, 3)
+	assert.EqualValues(t, 1, tree[0]["value"])
+	assert.Equal(t, "two", tree[1]["value"])
+	assert.Equal(t, "NaN", tree[2]["value"])
+}
+
+func TestNewJSONRenderer_OneDocumentPerValue(t *testing.T) {
+	var sb strings.Builder
+	New(WithRenderer(NewJSONRenderer)).Fdump(&sb, 1, map[string]bool{"ok": true})
+
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
+
+func TestDumpJSONTree_MapKeys(t *testing.T) {
+	type point struct{ X int }
+	out := DumpJSONTree(map[any]int{"1": 1, 1: 2, point{X: 3}: 3})
+
+	var tree struct {
+		Entries []struct {
+			Key any `json:"key"`
+		} `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &tree))
+	require.Len(t, tree.Entries, 3)
+	assert.Equal(t, "godump.point", tree.Entries[0].Key.(map[string]any)["type"])
+	assert.Equal(t, float64(1), tree.Entries[1].Key)
+	assert.Equal(t, "1", tree.Entries[2].Key)
+}
@@ -0,0 +1,76 @@
+package godump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// guardRenderer forwards events to the renderer of a dump while tracking the
+// values it has open, so a node whose rendering panics can be closed cleanly.
+type guardRenderer struct {
+	Renderer
+	open []Node
+}
+
+func (g *guardRenderer) BeginValue(n Node) {
+	g.open = append(g.open, n)
+	g.Renderer.BeginValue(n)
+}
+
+func (g *guardRenderer) EndValue(n Node) {
+	g.open = g.open[:len(g.open)-1]
+	g.Renderer.EndValue(n)
+}
+
+// nodeMark is the state of a dump when it starts rendering a node, restored
+// if rendering the node panics.
+type nodeMark struct {
+	open     int
+	path     int
+	proxied  int
+	shown    int
+	included bool
+	inKey    bool
+}
+
+// mark records the state of the dump before rendering a node.
+func (s *dumpState) mark() nodeMark {
+	return nodeMark{
+		open:     len(s.guard.open),
+		path:     len(s.path),
+		proxied:  len(s.proxied),
+		shown:    len(s.shown),
+		included: s.included,
+		inKey:    s.inKey,
+	}
+}
+
+// recoverNode is deferred by printValue. If rendering v panicked, it closes
+// the values v left open, renders a <panic: ...> marker in place of v, or of
+// the part of v that failed, and lets the dump continue with the next node.
+func (s *dumpState) recoverNode(v reflect.Value, m nodeMark) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	s.path = s.path[:m.path]
+	s.proxied = s.proxied[:m.proxied]
+	clear(s.shown[m.shown:])
+	s.shown = s.shown[:m.shown]
+	s.included, s.inKey = m.included, m.inKey
+	s.inline = false
+	for len(s.guard.open) > m.open+1 {
+		s.r.EndValue(s.guard.open[len(s.guard.open)-1])
+	}
+
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	n := s.node(v)
+	n.Form = FormPanic
+	s.r.Scalar(n, fmt.Sprintf("panic: %v", p))
+	if len(s.guard.open) > m.open {
+		s.r.EndValue(s.guard.open[len(s.guard.open)-1])
+	}
+}
@@ -0,0 +1,89 @@
+package godump
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type panickyDumpable struct{}
+
+func (panickyDumpable) DumpValue() any { panic("dump failed") }
+
+type panickyError struct{}
+
+func (panickyError) Error() string { panic(errors.New("no message")) }
+
+type panickyRendered struct{ N int }
+
+func init() {
+	RegisterTypeRenderer(reflect.TypeFor[panickyRendered](), func(v reflect.Value, r Renderer) {
+		r.BeginValue(Node{Type: v.Type(), Kind: v.Kind()})
+		r.StructField("N", true)
+		panic("half rendered")
+	})
+}
+
+func TestPanic_FieldContinues(t *testing.T) {
+	type holder struct {
+		Before string
+		Bad    panickyDumpable
+		After  string
+	}
+	var out string
+	require.NotPanics(t, func() {
+		out = New(WithColor(false)).DumpStr(holder{Before: "a", After: "b"})
+	})
+
+	assert.Regexp(t, `\+Bad +=> <panic: dump failed> #godump.panickyDumpable`, out)
+	assert.Contains(t, out, `"a"`)
+	assert.Contains(t, out, `"b"`)
+}
+
+func TestPanic_SliceElement(t *testing.T) {
+	out := New(WithColor(false)).DumpStr([]any{1, panickyError{}, 3})
+
+	assert.Contains(t, out, "1 => #godump.panickyError")
+	assert.Contains(t, out, "+Error() => <panic: no message> #godump.panickyError")
+	assert.Contains(t, out, "2 => 3")
+}
+
+func TestPanic_ClosesOpenValues(t *testing.T) {
+	out := New(WithColor(false)).DumpStr([]any{panickyRendered{}, "next"})
+
+	assert.Contains(t, out, "+N => <panic: half rendered> #godump.panickyRendered")
+	assert.Contains(t, out, `1 => "next"`)
+	assert.Equal(t, 2, countLines(out, "}")+countLines(out, "]"))
+}
+
+func TestPanic_JSONTree(t *testing.T) {
+	out := DumpJSONTree(map[string]any{"bad": panickyDumpable{}, "good": 1})
+
+	assert.Contains(t, out, `"form": "panic"`)
+	assert.Contains(t, out, `"value": "panic: dump failed"`)
+	assert.Contains(t, out, `"key": "good"`)
+}
+
+func TestPanic_HTML(t *testing.T) {
+	var html string
+	require.NotPanics(t, func() {
+		html = DumpHTML([]any{panickyDumpable{}})
+	})
+
+	assert.Contains(t, html, "panic: dump failed")
+}
+
+// countLines counts the lines of out consisting only of closer.
+func countLines(out, closer string) int {
+	n := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == closer {
+			n++
+		}
+	}
+	return n
+}
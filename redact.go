@@ -0,0 +1,318 @@
+package godump
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultRedactMask replaces redacted values and secrets found in strings.
+const defaultRedactMask = "[REDACTED]"
+
+// defaultSensitiveNames are the field names and map keys whose values are
+// redacted by default. Names are compared after normalization, so "api_key",
+// "X-Api-Key" and "APIKey" all end with "apikey".
+var defaultSensitiveNames = []string{
+	"password",
+	"passwd",
+	"secret",
+	"secrets",
+	"secretkey",
+	"token",
+	"authorization",
+	"apikey",
+	"accesskey",
+	"privatekey",
+	"credential",
+	"credentials",
+	"cookie",
+	"cookies",
+}
+
+// secretPattern finds secrets inside string values. If valid is set, a match
+// is only redacted when valid accepts it.
+type secretPattern struct {
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// defaultSecretPatterns find JSON Web Tokens, AWS access key ids and credit
+// card numbers.
+var defaultSecretPatterns = []secretPattern{
+	{re: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhnValid},
+}
+
+// WithRedaction turns redaction of sensitive fields, map entries and secrets
+// in strings on or off. It is on by default.
+func WithRedaction(enabled bool) Option {
+	return func(d *Dumper) {
+		d.redact = enabled
+	}
+}
+
+// WithRedactFields adds names to the list of sensitive field names and map
+// keys. A field or string map key is redacted when its name, lowercased and
+// stripped of everything but letters and digits, ends with one of the names,
+// so "token" matches AccessToken but not MaxTokens or TokenCount.
+func WithRedactFields(names ...string) Option {
+	return func(d *Dumper) {
+		for _, name := range names {
+			d.sensitiveNames = append(d.sensitiveNames, normalizeName(name))
+		}
+	}
+}
+
+// WithRedactPatterns adds patterns that find secrets in string values. The
+// parts of strings, including the output of String methods, that match are
+// replaced by the mask.
+func WithRedactPatterns(patterns ...*regexp.Regexp) Option {
+	return func(d *Dumper) {
+		for _, re := range patterns {
+			d.secretPatterns = append(d.secretPatterns, secretPattern{re: re})
+		}
+	}
+}
+
+// WithRedactMask sets the text shown in place of redacted values. Defaults to "[REDACTED]".
+func WithRedactMask(mask string) Option {
+	return func(d *Dumper) {
+		d.redactMask = mask
+	}
+}
+
+// normalizeName lowercases name and strips everything but letters and digits.
+func normalizeName(name string) string {
+	return string(appendNormalized(nil, name))
+}
+
+// appendNormalized appends the normalized form of name to b.
+func appendNormalized(b []byte, name string) []byte {
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b = utf8.AppendRune(b, unicode.ToLower(r))
+		}
+	}
+	return b
+}
+
+// sensitiveName reports whether values named name are redacted.
+func (d *Dumper) sensitiveName(name string) bool {
+	if !d.redact {
+		return false
+	}
+	var buf [64]byte
+	norm := appendNormalized(buf[:0], name)
+	for _, s := range d.sensitiveNames {
+		if s != "" && bytes.HasSuffix(norm, []byte(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveKey reports whether the value of the map key key is redacted: the
+// key must be a string, possibly held in an interface, with a sensitive name.
+func (d *Dumper) sensitiveKey(key reflect.Value) bool {
+	if key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	return key.Kind() == reflect.String && d.sensitiveName(key.String())
+}
+
+// sensitiveNormalized is sensitiveName for a name already normalized.
+func (d *Dumper) sensitiveNormalized(norm string) bool {
+	if !d.redact {
+		return false
+	}
+	for _, s := range d.sensitiveNames {
+		if s != "" && strings.HasSuffix(norm, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces the secrets found in s by the mask.
+func (d *Dumper) redactString(s string) string {
+	if !d.redact {
+		return s
+	}
+	for _, p := range d.secretPatterns {
+		if !p.re.MatchString(s) {
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(m string) string {
+			if p.valid != nil && !p.valid(m) {
+				return m
+			}
+			return d.redactMask
+		})
+	}
+	return s
+}
+
+// printRedacted renders the mask in place of v.
+func (s *dumpState) printRedacted(v reflect.Value) {
+	n := s.node(v)
+	n.Form = FormRedacted
+	n.Len, n.Cap = 0, 0
+	s.r.Scalar(n, s.redactMask)
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum used by
+// credit card numbers.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactJSON rewrites the JSON document data with the values of sensitive
+// keys, and of the keys in tagged, replaced by the mask and the secrets in
+// its strings masked. The order of the keys is kept.
+func (d *Dumper) redactJSON(data []byte, tagged map[string]bool) ([]byte, error) {
+	type container struct {
+		object bool
+		items  int  // members or elements written so far
+		value  bool // in an object, the next token is a member's value
+	}
+	var stack []container
+	out := make([]byte, 0, len(data))
+
+	// next writes the separator due before the next element or member.
+	next := func() {
+		if len(stack) == 0 {
+			return
+		}
+		c := &stack[len(stack)-1]
+		if c.object && c.value {
+			c.value = false
+			return
+		}
+		if c.items > 0 {
+			out = append(out, ',')
+		}
+		c.items++
+	}
+	appendString := func(s string) {
+		b, _ := json.Marshal(s)
+		out = append(out, b...)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				next()
+				stack = append(stack, container{object: tok == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+			out = append(out, byte(tok))
+		case string:
+			if c := len(stack) - 1; c >= 0 && stack[c].object && !stack[c].value {
+				next()
+				appendString(tok)
+				out = append(out, ':')
+				stack[c].value = true
+				if tagged[tok] || d.sensitiveName(tok) {
+					var skipped json.RawMessage
+					if err := dec.Decode(&skipped); err != nil {
+						return nil, err
+					}
+					next()
+					appendString(d.redactMask)
+				}
+				continue
+			}
+			next()
+			appendString(d.redactString(tok))
+		case json.Number:
+			next()
+			out = append(out, tok...)
+		case bool:
+			next()
+			out = strconv.AppendBool(out, tok)
+		case nil:
+			next()
+			out = append(out, "null"...)
+		}
+	}
+}
+
+// redactedJSONKeys returns the JSON names of the fields tagged dump:"redact"
+// in the types of vs and the types they contain. Values held in interfaces
+// are only known by their own type when they are one of vs.
+func redactedJSONKeys(vs []any) map[string]bool {
+	keys := map[string]bool{}
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t != nil && !seen[t] {
+			seen[t] = true
+			switch t.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Array:
+				t = t.Elem()
+				continue
+			case reflect.Map:
+				walk(t.Key())
+				t = t.Elem()
+				continue
+			case reflect.Struct:
+				for _, f := range cachedFields(t) {
+					if f.tag.redact {
+						keys[jsonName(f.field)] = true
+					}
+					walk(f.field.Type)
+				}
+			}
+			return
+		}
+	}
+	for _, v := range vs {
+		walk(reflect.TypeOf(v))
+	}
+	return keys
+}
+
+// jsonName returns the key encoding/json uses for the field f.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,95 @@
+package godump
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// refKey identifies a value that can be reached more than once: the target of
+// a pointer, a map, or the backing array of a slice. The type is part of the
+// key so a pointer to a struct and a pointer to its first field stay distinct.
+// The address is kept as a pointer rather than a uintptr so the tables of a
+// dump keep the values they track alive: a temporary value, such as a proxy
+// returned by a formatter, could otherwise be collected and its address
+// reused by the next one, which would then pass for a back-reference.
+type refKey struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+	len int
+}
+
+// maxTrackedRefs caps the number of values the tables of a dump track, so
+// the memory a dump uses does not grow with the size of the value. Past the
+// cap, values met for the first time are no longer tracked: shared ones are
+// dumped again, and cycles through them stop at the maximum depth.
+const maxTrackedRefs = 1 << 15
+
+// refKeyOf returns the reference key of v, if v is a value that can be shared.
+func refKeyOf(v reflect.Value) (refKey, bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		// Pointers to zero-sized values may all share one address.
+		if v.IsNil() || v.Type().Elem().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{ptr: v.UnsafePointer(), typ: v.Type()}, true
+	case reflect.Map:
+		if v.IsNil() || v.Len() == 0 {
+			return refKey{}, false
+		}
+		return refKey{ptr: v.UnsafePointer(), typ: v.Type()}, true
+	case reflect.Slice:
+		if v.Len() == 0 || v.Type().Elem().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{ptr: v.UnsafePointer(), typ: v.Type(), len: v.Len()}, true
+	default:
+		return refKey{}, false
+	}
+}
+
+// trackRef records a visit to v and reports whether v was already dumped,
+// along with the id to refer to it by. On the first visit of a value that the
+// scanning pass saw more than once, id is the &N marker to print before it.
+// A value already dumped without a marker has an id of 0.
+func (s *dumpState) trackRef(v reflect.Value) (id int, seen bool) {
+	key, ok := refKeyOf(v)
+	if !ok {
+		return 0, false
+	}
+
+	if s.scanning {
+		visits, ok := s.visits[key]
+		if !ok && len(s.visits) >= maxTrackedRefs {
+			return 0, false
+		}
+		s.visits[key] = visits + 1
+		return 0, visits > 0
+	}
+
+	if id, ok := s.refs[key]; ok {
+		// An id of 0 means the scanning pass did not predict this visit, as
+		// it does not follow DumpValue results and formatter proxies.
+		return id, true
+	}
+
+	if len(s.refs) >= maxTrackedRefs {
+		return 0, false
+	}
+	if s.visits[key] > 1 {
+		id = s.nextRefID
+		s.nextRefID++
+	}
+	s.refs[key] = id
+	return id, false
+}
+
+// printReference renders a back-reference to the value with the given id, or
+// a repeat marker if that value was dumped without an &N marker to point to.
+func (s *dumpState) printReference(id int) {
+	if id == 0 {
+		s.r.Truncated(TruncatedRepeat)
+		return
+	}
+	s.r.Reference(id)
+}
@@ -0,0 +1,169 @@
+package godump
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycle_PointerMarksOriginal(t *testing.T) {
+	type Node struct {
+		Next *Node
+	}
+	n := &Node{}
+	n.Next = n
+
+	out := stripANSI(DumpStr(n))
+	assert.Contains(t, out, "&1 #godump.Node")
+	assert.Contains(t, out, "↩︎ &1")
+}
+
+func TestCycle_MapContainsItself(t *testing.T) {
+	m := map[string]any{"name": "root"}
+	m["self"] = m
+
+	out := stripANSI(DumpStr(m))
+	assert.Contains(t, out, "&1 {")
+	assert.Contains(t, out, `"self" => ↩︎ &1`)
+	assert.NotContains(t, out, "max depth")
+}
+
+func TestCycle_SliceContainsItselfThroughInterface(t *testing.T) {
+	s := make([]any, 2)
+	s[0] = "first"
+	s[1] = s
+
+	out := stripANSI(DumpStr(s))
+	assert.Contains(t, out, "&1 [")
+	assert.Contains(t, out, "1 => ↩︎ &1")
+	assert.NotContains(t, out, "max depth")
+}
+
+func TestCycle_NonAddressablePointerChain(t *testing.T) {
+	type Node struct {
+		Name string
+		Next *Node
+	}
+	a := &Node{Name: "a"}
+	b := &Node{Name: "b", Next: a}
+	a.Next = b
+
+	out := stripANSI(DumpStr(a))
+	assert.Contains(t, out, "&1 #godump.Node")
+	assert.Contains(t, out, "↩︎ &1")
+	assert.NotContains(t, out, "max depth")
+}
+
+func TestSharedPointerReferencesFirstOccurrence(t *testing.T) {
+	type Leaf struct {
+		Name string
+	}
+	type Pair struct {
+		Left  *Leaf
+		Right *Leaf
+	}
+	leaf := &Leaf{Name: "shared"}
+
+	out := stripANSI(DumpStr(Pair{Left: leaf, Right: leaf}))
+	assert.Contains(t, out, "&1 #godump.Leaf")
+	assert.Contains(t, out, "↩︎ &1")
+	assert.Equal(t, 1, strings.Count(out, `"shared"`))
+}
+
+func TestUnsharedValuesHaveNoMarkers(t *testing.T) {
+	type Leaf struct {
+		Name string
+	}
+	type Pair struct {
+		Left  *Leaf
+		Right *Leaf
+		Tags  map[string]int
+		Items []int
+	}
+
+	out := stripANSI(DumpStr(Pair{
+		Left:  &Leaf{Name: "l"},
+		Right: &Leaf{Name: "r"},
+		Tags:  map[string]int{"a": 1},
+		Items: []int{1, 2},
+	}))
+	assert.NotContains(t, out, "&")
+}
+
+func TestRefKeyOf_ZeroSized(t *testing.T) {
+	type empty struct{}
+	_, ok := refKeyOf(reflect.ValueOf(&empty{}))
+	assert.False(t, ok)
+
+	_, ok = refKeyOf(reflect.ValueOf([]int{}))
+	assert.False(t, ok)
+}
+
+func TestTrackRef_TablesAreBounded(t *testing.T) {
+	values := make([]int, maxTrackedRefs+100)
+	d := New()
+
+	scan := newDumpState(d, discardRenderer{})
+	scan.scanning = true
+	for i := range values {
+		scan.trackRef(reflect.ValueOf(&values[i]))
+	}
+	assert.Len(t, scan.visits, maxTrackedRefs)
+	_, seen := scan.trackRef(reflect.ValueOf(&values[0]))
+	assert.True(t, seen, "values tracked before the cap are still found")
+
+	s := newDumpState(d, discardRenderer{})
+	s.visits, scan.visits = scan.visits, s.visits
+	scan.release()
+	for i := range values {
+		s.trackRef(reflect.ValueOf(&values[i]))
+	}
+	assert.Len(t, s.refs, maxTrackedRefs)
+	s.release()
+}
+
+func TestCycle_PastTrackingCapStopsAtMaxDepth(t *testing.T) {
+	type Node struct {
+		Next *Node
+	}
+	filler := make([]*int, maxTrackedRefs)
+	for i := range filler {
+		filler[i] = new(int)
+	}
+	n := &Node{}
+	n.Next = n
+
+	out := New(WithColor(false), WithMaxItems(len(filler)), WithMaxDepth(4)).DumpStr(filler, n)
+	assert.Contains(t, out, "... (max depth)")
+}
+
+// assertReferencesResolve checks that every ↩︎ &N in a dump points to a value
+// marked &N.
+func assertReferencesResolve(t *testing.T, out string) {
+	t.Helper()
+	for _, m := range regexp.MustCompile(`↩︎ &(\d+)`).FindAllStringSubmatch(out, -1) {
+		marks := regexp.MustCompile(`&`+m[1]+`\b`).FindAllStringIndex(out, -1)
+		refs := strings.Count(out, m[0]+" ") + strings.Count(out, m[0]+"\n")
+		assert.Greater(t, len(marks), refs, "%s has no matching &%s", m[0], m[1])
+	}
+}
+
+type selfView struct{ Self *selfView }
+
+func (c *selfView) DumpValue() any { return map[string]any{"self": c.Self} }
+
+func TestCycle_ThroughDumpValueHasNoDanglingReference(t *testing.T) {
+	c := &selfView{}
+	c.Self = c
+
+	out := New(WithColor(false)).DumpStr(c)
+	assertReferencesResolve(t, out)
+	assert.Contains(t, out, "... (already dumped)")
+
+	tree := DumpJSONTree(c)
+	assert.Contains(t, tree, `"truncated": "already dumped"`)
+	assert.NotContains(t, tree, `"ref"`)
+}
@@ -0,0 +1,429 @@
+package godump
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Form describes how a Node is displayed.
+type Form int
+
+const (
+	// FormValue is a regular value, rendered according to its kind.
+	FormValue Form = iota
+	// FormNil is a nil pointer, interface, map, slice, func or channel.
+	FormNil
+	// FormStringer is a value rendered through its String method. With
+	// StringerBoth it opens a value holding the String result and the fields.
+	FormStringer
+	// FormHexdump is a byte slice rendered as rows of a hex dump.
+	FormHexdump
+	// FormRedacted is a sensitive value replaced by the redaction mask.
+	FormRedacted
+	// FormInline is a small struct or array rendered on a single line.
+	FormInline
+	// FormFormatted is a value rendered by a dedicated formatter, such as time.Time.
+	FormFormatted
+	// FormError is an error rendered as its message, wrapped errors and stack trace.
+	FormError
+	// FormPanic is a value whose rendering panicked; the text describes the panic.
+	FormPanic
+)
+
+// Truncation identifies why part of a value was left out of a dump.
+type Truncation int
+
+const (
+	// TruncatedDepth marks a value nested deeper than the maximum depth.
+	TruncatedDepth Truncation = iota
+	// TruncatedItems marks the items of a slice, array or map past the maximum
+	// item count, or the bytes of a byte slice past the maximum byte count.
+	TruncatedItems
+	// TruncatedRepeat marks a value already dumped earlier without the &N
+	// marker a back-reference would point to, such as a value reached again
+	// through the result of a DumpValue method or a formatter.
+	TruncatedRepeat
+)
+
+// Node describes the value a Renderer is asked to render.
+type Node struct {
+	Type  reflect.Type // nil for invalid values
+	Kind  reflect.Kind
+	Form  Form
+	Len   int // length of strings, slices, arrays and maps
+	Cap   int // capacity of slices
+	ID    int // reference id other values point back to, 0 if none
+	Width int // length of the longest field name of a struct, for aligning its fields
+
+	// Content is the format the bytes of a byte slice or array look like,
+	// such as "json", "protobuf" or "gzip", or "" if unknown.
+	Content string
+	// Decoded is the format of the string or json.RawMessage the value was
+	// decoded from, "json" or "xml", or "" if it was not decoded.
+	Decoded string
+}
+
+// Renderer turns the events produced while walking a value into output.
+// A Renderer is created for a single dump, so it may keep state such as the
+// current nesting depth.
+type Renderer interface {
+	// Header renders the location the dump was called from.
+	Header(file string, line int)
+	// BeginValue opens a struct, map, slice, array or hex dump.
+	BeginValue(n Node)
+	// EndValue closes the value opened by the matching BeginValue.
+	EndValue(n Node)
+	// StructField starts a struct field; its value is rendered next.
+	StructField(name string, exported bool)
+	// MapKey starts a map entry; its key is rendered next.
+	MapKey()
+	// MapValue follows the key of a map entry; its value is rendered next.
+	MapValue()
+	// SliceIndex starts a slice or array element; its value is rendered next.
+	SliceIndex(index int)
+	// HexRow renders one row of a hex dump.
+	HexRow(offset int, hex, ascii string)
+	// Scalar renders a leaf value from its text form.
+	Scalar(n Node, text string)
+	// Reference renders a back-reference to the value marked with id.
+	Reference(id int)
+	// Truncated renders a marker for output that was left out.
+	Truncated(t Truncation)
+}
+
+// RendererFunc creates the Renderer for a single dump writing to w.
+type RendererFunc func(w io.Writer) Renderer
+
+// WithRenderer sets the renderer used by Dump, Fdump, DumpStr and Dd,
+// overriding the ANSI or plain-text renderer picked by WithColor and WithTheme.
+func WithRenderer(fn RendererFunc) Option {
+	return func(d *Dumper) {
+		d.newRenderer = fn
+	}
+}
+
+// NewANSIRenderer returns a Renderer producing text colorized with ANSI escape
+// codes in the colors of the theme, approximated to the given color mode.
+// ColorAuto is treated as Color256, ColorNone produces uncolored text.
+func NewANSIRenderer(w io.Writer, theme Theme, mode ColorMode) Renderer {
+	r := &textRenderer{w: w, escape: identity}
+	r.open, r.close = theme.ansiStyles(mode)
+	return r
+}
+
+// NewPlainRenderer returns a Renderer producing uncolored text.
+func NewPlainRenderer(w io.Writer) Renderer {
+	return &textRenderer{w: w, escape: identity}
+}
+
+// identity returns s unchanged.
+func identity(s string) string {
+	return s
+}
+
+// textRenderer renders values as the indented, line-oriented text format shared
+// by the ANSI, plain-text and HTML outputs. It writes each event as it comes,
+// aligning the "=>" of struct fields with the Width of the struct.
+type textRenderer struct {
+	w      io.Writer
+	open   [roleCount]string // markup that starts text of each role
+	close  [roleCount]string // markup that ends text of each role
+	escape func(string) string
+	gutter string // written at the start of every line, used by diffs
+	depth  int
+	inKey  int    // number of map keys being rendered
+	inline int    // number of FormInline values being rendered
+	first  bool   // no item of the innermost inline value was rendered yet
+	widths []int  // field name width of each open value
+	num    []byte // scratch space for formatting numbers
+}
+
+// spaces is sliced to write indentation and padding without allocating.
+const spaces = "                                                                "
+
+// write writes the text styled for the given role.
+func (r *textRenderer) write(ro role, text string) {
+	if r.open[ro] != "" {
+		io.WriteString(r.w, r.open[ro])
+	}
+	io.WriteString(r.w, r.escape(text))
+	if r.close[ro] != "" {
+		io.WriteString(r.w, r.close[ro])
+	}
+}
+
+// writeInt writes n in the given base, zero-padded to width digits and styled
+// for the given role.
+func (r *textRenderer) writeInt(ro role, n, base, width int) {
+	r.num = strconv.AppendInt(r.num[:0], int64(n), base)
+	for len(r.num) < width {
+		r.num = append(r.num, 0)
+		copy(r.num[1:], r.num)
+		r.num[0] = '0'
+	}
+	if r.open[ro] != "" {
+		io.WriteString(r.w, r.open[ro])
+	}
+	r.w.Write(r.num)
+	if r.close[ro] != "" {
+		io.WriteString(r.w, r.close[ro])
+	}
+}
+
+// pad writes n spaces.
+func (r *textRenderer) pad(n int) {
+	for n > 0 {
+		k := min(n, len(spaces))
+		io.WriteString(r.w, spaces[:k])
+		n -= k
+	}
+}
+
+// newline starts a new line indented to the given depth.
+func (r *textRenderer) newline(depth int) {
+	io.WriteString(r.w, "\n")
+	if r.gutter != "" {
+		io.WriteString(r.w, r.gutter)
+	}
+	r.pad(depth * indentWidth)
+}
+
+// done terminates the line of a top-level value once it is complete.
+func (r *textRenderer) done() {
+	if r.depth == 0 {
+		io.WriteString(r.w, "\n")
+	}
+}
+
+// marker writes the &N marker of a value other values point back to.
+func (r *textRenderer) marker(n Node) {
+	if n.ID > 0 {
+		r.write(roleReference, fmt.Sprintf("&%d ", n.ID))
+	}
+}
+
+func (r *textRenderer) Header(file string, line int) {
+	r.write(roleMeta, fmt.Sprintf("<#dump // %s:%d", file, line))
+	io.WriteString(r.w, "\n")
+}
+
+// separate writes the separator before an item of an inline value and
+// reports whether it did.
+func (r *textRenderer) separate() bool {
+	if r.inline == 0 {
+		return false
+	}
+	if !r.first {
+		r.write(roleNone, ", ")
+	}
+	r.first = false
+	return true
+}
+
+// valueRole returns the role of a scalar, or roleKey for scalars that are map keys.
+func (r *textRenderer) valueRole(ro role) role {
+	if r.inKey > 0 {
+		return roleKey
+	}
+	return ro
+}
+
+func (r *textRenderer) BeginValue(n Node) {
+	r.marker(n)
+	switch {
+	case n.Form == FormInline:
+		r.inline++
+		r.first = true
+		if n.Kind == reflect.Struct {
+			r.write(roleType, "#"+typeName(n.Type))
+			r.write(roleNone, " {")
+		} else {
+			r.write(roleNone, "[")
+		}
+	case n.Form == FormHexdump:
+		if n.Kind == reflect.Array {
+			r.write(roleNone, fmt.Sprintf("(%s) (len=%d) {", n.Type, n.Len))
+		} else {
+			r.write(roleNone, fmt.Sprintf("(%s) (len=%d cap=%d) {", n.Type, n.Len, n.Cap))
+		}
+		r.note(n)
+	case n.Kind == reflect.Struct, n.Form == FormError, n.Form == FormStringer:
+		r.write(roleType, "#"+typeName(n.Type))
+		r.note(n)
+		r.write(roleNone, " ")
+	case n.Kind == reflect.Map:
+		r.write(roleNone, "{")
+		r.note(n)
+	default:
+		r.write(roleNone, "[")
+		r.note(n)
+	}
+	r.depth++
+	r.widths = append(r.widths, n.Width)
+}
+
+func (r *textRenderer) EndValue(n Node) {
+	r.depth--
+	r.widths = r.widths[:len(r.widths)-1]
+	if n.Form == FormInline {
+		r.inline--
+	} else {
+		r.newline(r.depth)
+	}
+	r.write(roleNone, closer(n))
+	r.done()
+}
+
+// note comments on the format n was decoded from, or the format its bytes
+// look like.
+func (r *textRenderer) note(n Node) {
+	switch {
+	case n.Decoded != "":
+		r.write(roleMeta, " // decoded from "+n.Decoded)
+	case n.Content != "":
+		r.write(roleMeta, " // looks like "+n.Content)
+	}
+}
+
+// closer returns the bracket closing the value opened for n.
+func closer(n Node) string {
+	if (n.Kind == reflect.Slice || n.Kind == reflect.Array) && (n.Form == FormValue || n.Form == FormInline) {
+		return "]"
+	}
+	return "}"
+}
+
+func (r *textRenderer) StructField(name string, exported bool) {
+	symbol := "+"
+	if !exported {
+		symbol = "-"
+	}
+	if r.separate() {
+		r.write(rolePunctuation, symbol)
+		r.write(roleField, name)
+		r.write(roleNone, ": ")
+		return
+	}
+	r.newline(r.depth)
+	r.write(rolePunctuation, symbol)
+	r.write(roleField, name)
+	r.alignField(name)
+	io.WriteString(r.w, " => ")
+}
+
+// alignField writes the spaces aligning the "=>" after a field name with
+// those of the other fields of the innermost struct.
+func (r *textRenderer) alignField(name string) {
+	if len(r.widths) > 0 {
+		r.pad(r.widths[len(r.widths)-1] - utf8.RuneCountInString(name))
+	}
+}
+
+func (r *textRenderer) MapKey() {
+	r.newline(r.depth)
+	r.write(roleNone, " ")
+	r.inKey++
+}
+
+func (r *textRenderer) MapValue() {
+	r.inKey--
+	r.write(roleNone, " => ")
+}
+
+func (r *textRenderer) SliceIndex(index int) {
+	if r.separate() {
+		return
+	}
+	r.newline(r.depth)
+	r.writeInt(roleNumber, index, 10, 0)
+	r.write(roleNone, " => ")
+}
+
+func (r *textRenderer) HexRow(offset int, hex, ascii string) {
+	r.newline(r.depth)
+	r.writeInt(roleOffset, offset, 16, 8)
+	r.write(roleOffset, "  ")
+	r.write(roleNumber, hex)
+	r.write(roleNone, " ")
+	r.write(roleMeta, "| ")
+	r.write(roleString, ascii)
+	r.write(roleMeta, " |")
+}
+
+func (r *textRenderer) Scalar(n Node, text string) {
+	r.marker(n)
+	switch {
+	case n.Form == FormNil:
+		r.write(roleType, typeName(n.Type))
+		r.write(roleNil, "(nil)")
+	case n.Form == FormStringer, n.Form == FormFormatted:
+		r.write(roleString, text)
+		r.write(roleType, " #"+typeName(n.Type))
+		r.note(n)
+	case n.Form == FormRedacted:
+		r.write(roleMeta, text)
+	case n.Form == FormPanic:
+		r.write(roleMeta, "<"+text+">")
+		if n.Type != nil {
+			r.write(roleType, " #"+typeName(n.Type))
+		}
+	default:
+		switch n.Kind {
+		case reflect.String:
+			r.write(r.valueRole(rolePunctuation), `"`)
+			r.write(r.valueRole(roleString), text)
+			r.write(r.valueRole(rolePunctuation), `"`)
+		case reflect.Bool:
+			r.write(r.valueRole(roleBool), text)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+			r.write(r.valueRole(roleNumber), text)
+		case reflect.Chan, reflect.UnsafePointer:
+			r.write(roleType, typeName(n.Type))
+			r.write(roleNone, "(")
+			r.write(roleNumber, text)
+			r.write(roleNone, ")")
+		default:
+			r.write(roleMeta, text)
+		}
+	}
+	r.done()
+}
+
+func (r *textRenderer) Reference(id int) {
+	r.write(roleReference, fmt.Sprintf("↩︎ &%d", id))
+	r.done()
+}
+
+func (r *textRenderer) Truncated(t Truncation) {
+	switch t {
+	case TruncatedItems:
+		r.newline(r.depth)
+		r.write(roleMeta, "... (truncated)")
+	case TruncatedRepeat:
+		r.write(roleMeta, "... (already dumped)")
+		r.done()
+	default:
+		r.write(roleMeta, "... (max depth)")
+		r.done()
+	}
+}
+
+// discardRenderer drops every event. It backs the scanning pass of a dump.
+type discardRenderer struct{}
+
+func (discardRenderer) Header(string, int)         {}
+func (discardRenderer) BeginValue(Node)            {}
+func (discardRenderer) EndValue(Node)              {}
+func (discardRenderer) StructField(string, bool)   {}
+func (discardRenderer) MapKey()                    {}
+func (discardRenderer) MapValue()                  {}
+func (discardRenderer) SliceIndex(int)             {}
+func (discardRenderer) HexRow(int, string, string) {}
+func (discardRenderer) Scalar(Node, string)        {}
+func (discardRenderer) Reference(int)              {}
+func (discardRenderer) Truncated(Truncation)       {}
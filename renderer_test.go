@@ -0,0 +1,93 @@
+package godump
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRenderer records the events it receives, one per line.
+type eventRenderer struct {
+	w io.Writer
+}
+
+func (r *eventRenderer) Header(file string, line int) {}
+func (r *eventRenderer) BeginValue(n Node)            { fmt.Fprintf(r.w, "begin %s #%d\n", n.Kind, n.ID) }
+func (r *eventRenderer) EndValue(n Node)              { fmt.Fprintf(r.w, "end %s\n", n.Kind) }
+func (r *eventRenderer) StructField(name string, exported bool) {
+	fmt.Fprintf(r.w, "field %s %t\n", name, exported)
+}
+func (r *eventRenderer) MapKey()              { fmt.Fprintln(r.w, "key") }
+func (r *eventRenderer) MapValue()            { fmt.Fprintln(r.w, "value") }
+func (r *eventRenderer) SliceIndex(index int) { fmt.Fprintf(r.w, "index %d\n", index) }
+func (r *eventRenderer) HexRow(offset int, hex, ascii string) {
+	fmt.Fprintf(r.w, "hex %d %q\n", offset, ascii)
+}
+func (r *eventRenderer) Scalar(n Node, text string) { fmt.Fprintf(r.w, "scalar %s %q\n", n.Kind, text) }
+func (r *eventRenderer) Reference(id int)           { fmt.Fprintf(r.w, "ref %d\n", id) }
+func (r *eventRenderer) Truncated(t Truncation)     { fmt.Fprintf(r.w, "truncated %d\n", t) }
+
+func TestWithRenderer_CustomRenderer(t *testing.T) {
+	type Node struct {
+		Name string
+		tags []int
+		Next *Node
+	}
+	n := &Node{Name: "root", tags: []int{7}}
+	n.Next = n
+
+	d := New(WithRenderer(func(w io.Writer) Renderer { return &eventRenderer{w: w} }))
+	out := d.DumpStr(n)
+
+	expected := strings.Join([]string{
+		"begin struct #1",
+		"field Name true",
+		`scalar string "root"`,
+		"field tags false",
+		"begin slice #0",
+		"index 0",
+		`scalar int "7"`,
+		"end slice",
+		"field Next true",
+		"ref 1",
+		"end struct",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, out)
+}
+
+func TestRenderer_TruncationEvents(t *testing.T) {
+	d := New(
+		WithMaxItems(1),
+		WithRenderer(func(w io.Writer) Renderer { return &eventRenderer{w: w} }),
+	)
+	out := d.DumpStr([]int{1, 2, 3})
+	assert.Contains(t, out, fmt.Sprintf("truncated %d", TruncatedItems))
+}
+
+func TestPlainRenderer_NoEscapeCodes(t *testing.T) {
+	var sb strings.Builder
+	d := New(WithRenderer(NewPlainRenderer))
+	d.Fdump(&sb, map[string]int{"a": 1})
+
+	assert.NotContains(t, sb.String(), "\033[")
+	assert.Contains(t, sb.String(), `"a" => 1`)
+}
+
+func TestANSIRenderer_EmitsEscapeCodes(t *testing.T) {
+	d := New(WithColor(false), WithRenderer(func(w io.Writer) Renderer {
+		return NewANSIRenderer(w, ThemeDark, Color256)
+	}))
+	assert.Contains(t, d.DumpStr(42), "\033[")
+}
+
+func TestHTMLRenderer_EscapesText(t *testing.T) {
+	html := DumpHTML(map[string]string{"<key>": "<script>alert(1)</script>"})
+
+	assert.NotContains(t, html, "<script>alert(1)</script>")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.Contains(t, html, "&lt;key&gt;")
+}
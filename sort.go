@@ -0,0 +1,112 @@
+package godump
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// maxCompareDepth bounds how deeply compareValues follows pointers and
+// interfaces, so self-referencing keys cannot recurse forever.
+const maxCompareDepth = 8
+
+// WithSortedMaps controls whether map entries are dumped sorted by key, which
+// makes dumps of the same map identical across runs. It is on by default;
+// turning it off dumps entries in Go's randomized iteration order, which is
+// cheaper for large maps.
+func WithSortedMaps(enabled bool) Option {
+	return func(d *Dumper) {
+		d.sortMaps = enabled
+	}
+}
+
+// sortMapKeys sorts map keys deterministically: numbers numerically, strings
+// lexically, false before true, structs and arrays element by element, and
+// interfaces by dynamic type name and then by value. Pointers and channels
+// are ordered by the values they point to, falling back to their address.
+func sortMapKeys(keys []reflect.Value) {
+	slices.SortStableFunc(keys, func(a, b reflect.Value) int {
+		return compareValues(a, b, 0)
+	})
+}
+
+// compareValues returns -1, 0 or 1 depending on whether a sorts before, with
+// or after b. Both values have the same type unless they are interfaces.
+func compareValues(a, b reflect.Value, depth int) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := cmp.Compare(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(a.Complex()), imag(b.Complex()))
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		default:
+			return 1
+		}
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if c := compareValues(a.Field(i), b.Field(i), depth); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Array:
+		for i := range a.Len() {
+			if c := compareValues(a.Index(i), b.Index(i), depth); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		if c, done := compareNil(a, b); done {
+			return c
+		}
+		ea, eb := a.Elem(), b.Elem()
+		if c := cmp.Compare(typeName(ea.Type()), typeName(eb.Type())); c != 0 || ea.Type() != eb.Type() {
+			return c
+		}
+		return compareValues(ea, eb, depth+1)
+	case reflect.Ptr:
+		if c, done := compareNil(a, b); done {
+			return c
+		}
+		if depth < maxCompareDepth {
+			if c := compareValues(a.Elem(), b.Elem(), depth+1); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	case reflect.Chan, reflect.UnsafePointer:
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	default:
+		return 0
+	}
+}
+
+// compareNil orders nil before non-nil values. It reports whether either
+// value is nil, in which case the comparison is settled.
+func compareNil(a, b reflect.Value) (int, bool) {
+	switch an, bn := a.IsNil(), b.IsNil(); {
+	case an && bn:
+		return 0, true
+	case an:
+		return -1, true
+	case bn:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,88 @@
+package godump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keyOrder returns the map keys in the order they appear in the uncolored dump.
+func keyOrder(d *Dumper, m any) []string {
+	var keys []string
+	for _, line := range strings.Split(d.DumpStr(m), "\n") {
+		if key, _, ok := strings.Cut(strings.TrimSpace(line), " => "); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestSortedMaps_Ints(t *testing.T) {
+	m := map[int]bool{10: true, -3: true, 2: true, 100: true, 0: true}
+
+	assert.Equal(t, []string{"-3", "0", "2", "10", "100"}, keyOrder(New(WithColor(false)), m))
+}
+
+func TestSortedMaps_Strings(t *testing.T) {
+	m := map[string]int{"b": 1, "a": 2, "B": 3, "aa": 4}
+
+	assert.Equal(t, []string{`"B"`, `"a"`, `"aa"`, `"b"`}, keyOrder(New(WithColor(false)), m))
+}
+
+func TestSortedMaps_InterfaceKeys(t *testing.T) {
+	m := map[any]int{"b": 1, 2: 2, "a": 3, 1: 4, true: 5}
+
+	assert.Equal(t, []string{"true", "1", "2", `"a"`, `"b"`}, keyOrder(New(WithColor(false)), m))
+}
+
+func TestSortedMaps_StructKeys(t *testing.T) {
+	type point struct{ X, Y int }
+	m := map[point]int{{2, 1}: 1, {1, 2}: 2, {1, 1}: 3}
+
+	assert.Equal(t, []string{
+		"#godump.point {+X: 1, +Y: 1}",
+		"#godump.point {+X: 1, +Y: 2}",
+		"#godump.point {+X: 2, +Y: 1}",
+	}, keyOrder(New(WithColor(false)), m))
+}
+
+func TestSortedMaps_Deterministic(t *testing.T) {
+	m := map[string]int{}
+	for _, k := range strings.Split("the quick brown fox jumps over the lazy dog", " ") {
+		m[k] = len(k)
+	}
+	d := New(WithColor(false))
+	first := d.DumpStr(m)
+	for range 20 {
+		assert.Equal(t, first, d.DumpStr(m))
+	}
+}
+
+func TestWithSortedMaps_Disabled(t *testing.T) {
+	m := map[int]int{}
+	for i := range 50 {
+		m[i] = i
+	}
+	keys := keyOrder(New(WithColor(false), WithSortedMaps(false)), m)
+
+	assert.Len(t, keys, 50)
+	assert.ElementsMatch(t, keyOrder(New(WithColor(false)), m), keys)
+}
+
+func TestCompareValues(t *testing.T) {
+	compare := func(a, b any) int {
+		return compareValues(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem(), 0)
+	}
+	one, two := 1, 2
+
+	assert.Equal(t, -1, compare(1.5, 2.5))
+	assert.Equal(t, 1, compare(uint(3), uint(1)))
+	assert.Equal(t, -1, compare(false, true))
+	assert.Equal(t, -1, compare(nil, 0))
+	assert.Equal(t, -1, compare(complex(1, 1), complex(1, 2)))
+	assert.Equal(t, -1, compare([2]int{1, 2}, [2]int{1, 3}))
+	assert.Equal(t, -1, compare(&one, &two))
+	assert.Equal(t, 0, compare("x", "x"))
+}
@@ -0,0 +1,159 @@
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// StringerMode selects how values implementing fmt.Stringer are dumped.
+type StringerMode int
+
+const (
+	// StringerReplace renders the String result in place of the value.
+	StringerReplace StringerMode = iota
+	// StringerNone ignores String methods and dumps the value itself.
+	StringerNone
+	// StringerBoth renders the String result followed by the value itself.
+	StringerBoth
+)
+
+// WithStringers sets how values implementing fmt.Stringer are dumped.
+// Defaults to StringerReplace.
+func WithStringers(mode StringerMode) Option {
+	return func(d *Dumper) {
+		d.stringerMode = mode
+	}
+}
+
+// WithStringerTypes restricts the use of String methods to the given types
+// and pointers to them. All other values are dumped as if they had no String
+// method.
+func WithStringerTypes(types ...reflect.Type) Option {
+	return func(d *Dumper) {
+		d.stringerTypes = append(d.stringerTypes, types...)
+	}
+}
+
+// printStringer renders v using its String method and reports whether v is a
+// fmt.Stringer the options allow to use. The scanning pass never calls
+// String, and a panicking String method is rendered as a panic marker. In
+// StringerBoth mode, pointers are tracked like in printValue, so a value that
+// refers back to itself is rendered as a reference.
+func (s *dumpState) printStringer(v reflect.Value, depth int) bool {
+	if s.stringerMode == StringerNone || !v.IsValid() || !s.stringerAllowed(v.Type()) ||
+		slices.Contains(s.proxied, v.Type()) {
+		return false
+	}
+	str, ok := asStringer(v)
+	if !ok {
+		return false
+	}
+	if rv := reflect.ValueOf(str); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		n := s.node(v)
+		n.Form = FormNil
+		s.r.Scalar(n, "")
+		return true
+	}
+	p := v
+	for p.Kind() == reflect.Interface && !p.IsNil() {
+		p = p.Elem()
+	}
+	if s.stringerMode == StringerBoth && p.Kind() == reflect.Ptr {
+		// The value pointed to is dumped too, so a pointer that leads back to
+		// it must be rendered as a reference rather than dumped again. Maps
+		// and slices are tracked when printValue dumps them below.
+		if id, seen := s.trackRef(p); seen {
+			s.printReference(id)
+			return true
+		} else if id > 0 {
+			s.pendingID = id
+		}
+	}
+	n := s.node(v)
+
+	text, form := "", FormStringer
+	if !s.scanning {
+		var panicked bool
+		text, panicked = callString(str)
+		if panicked {
+			form = FormPanic
+		} else {
+			text = s.redactString(text)
+		}
+	}
+	if s.stringerMode != StringerBoth {
+		n.Form = form
+		s.r.Scalar(n, text)
+		return true
+	}
+
+	e := v
+	for (e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface) && !e.IsNil() {
+		e = e.Elem()
+	}
+	var fields []shownField
+	n.Form, n.Width = FormStringer, len("String()")
+	if e.Kind() == reflect.Struct {
+		fields = s.structFields(e)
+		n.Width = max(n.Width, fieldWidth(fields))
+	}
+	s.r.BeginValue(n)
+	s.r.StructField("String()", true)
+	if form == FormPanic {
+		s.r.Scalar(Node{Type: n.Type, Kind: n.Kind, Form: FormPanic}, text)
+	} else {
+		tv := reflect.ValueOf(text)
+		s.r.Scalar(s.node(tv), s.scalarText(tv))
+	}
+	if e.Kind() == reflect.Struct {
+		s.printFields(e, fields, depth)
+		s.releaseFields(fields)
+	} else {
+		s.r.StructField("Value", true)
+		s.proxied = append(s.proxied, e.Type())
+		s.printValue(e, depth+1)
+		s.proxied = s.proxied[:len(s.proxied)-1]
+	}
+	s.r.EndValue(n)
+	return true
+}
+
+// stringerAllowed reports whether String methods may be used for values of
+// type typ.
+func (d *Dumper) stringerAllowed(typ reflect.Type) bool {
+	if len(d.stringerTypes) == 0 {
+		return true
+	}
+	if typ.Kind() == reflect.Ptr && slices.Contains(d.stringerTypes, typ.Elem()) {
+		return true
+	}
+	return slices.Contains(d.stringerTypes, typ)
+}
+
+// callString calls String, recovering from a panic in it. If it panicked,
+// the returned text describes the panic.
+func callString(str fmt.Stringer) (text string, panicked bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			text, panicked = fmt.Sprintf("panic: %v", p), true
+		}
+	}()
+	return str.String(), false
+}
+
+// asStringer returns the fmt.Stringer implemented by the value, if any.
+func asStringer(v reflect.Value) (fmt.Stringer, bool) {
+	if !implements(v, implStringer) {
+		return nil, false
+	}
+	val := v
+	if !val.CanInterface() {
+		val = forceExported(val)
+	}
+	if !val.CanInterface() {
+		return nil, false
+	}
+	str, ok := val.Interface().(fmt.Stringer)
+	return str, ok
+}
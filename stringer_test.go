@@ -0,0 +1,110 @@
+package godump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type level int
+
+func (l level) String() string { return [...]string{"debug", "info"}[l] }
+
+type contact struct {
+	Name  string
+	Email string
+}
+
+func (c contact) String() string { return c.Name + " <" + c.Email + ">" }
+
+type brokenStringer struct{ ID int }
+
+func (brokenStringer) String() string { panic("boom") }
+
+type chainNode struct {
+	Name string
+	Next *chainNode
+}
+
+func (n *chainNode) String() string { return "node " + n.Name }
+
+func TestStringer_Default(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(contact{Name: "Alice", Email: "a@example.com"})
+
+	assert.Contains(t, out, "Alice <a@example.com> #godump.contact")
+	assert.NotContains(t, out, "+Name")
+}
+
+func TestStringer_None(t *testing.T) {
+	out := New(WithColor(false), WithStringers(StringerNone)).DumpStr(contact{Name: "Alice"})
+
+	assert.Contains(t, out, `+Name  => "Alice"`)
+	assert.NotContains(t, out, "Alice <")
+}
+
+func TestStringer_Both(t *testing.T) {
+	out := New(WithColor(false), WithStringers(StringerBoth)).DumpStr(contact{Name: "Alice", Email: "a@example.com"})
+
+	assert.Contains(t, out, "#godump.contact")
+	assert.Contains(t, out, `+String() => "Alice <a@example.com>"`)
+	assert.Contains(t, out, `+Name     => "Alice"`)
+	assert.Contains(t, out, `+Email    => "a@example.com"`)
+}
+
+func TestStringer_BothSelfReference(t *testing.T) {
+	n := &chainNode{Name: "a"}
+	n.Next = n
+
+	out := New(WithColor(false), WithStringers(StringerBoth), WithMaxDepth(4)).DumpStr(n)
+	assert.Contains(t, out, "&1")
+	assert.Contains(t, out, `+String() => "node a"`)
+	assert.Contains(t, out, "+Next     => ↩︎ &1")
+	assert.Equal(t, 1, strings.Count(out, "node a"))
+
+	// past the maximum depth, fields are truncated instead of dumped through String
+	a, b, c := &chainNode{Name: "a"}, &chainNode{Name: "b"}, &chainNode{Name: "c"}
+	a.Next, b.Next = b, c
+	out = New(WithColor(false), WithStringers(StringerBoth), WithMaxDepth(1)).DumpStr(a)
+	assert.Contains(t, out, "node b")
+	assert.NotContains(t, out, "node c")
+	assert.Contains(t, out, "... (max depth)")
+}
+
+func TestStringer_BothScalar(t *testing.T) {
+	out := New(WithColor(false), WithStringers(StringerBoth)).DumpStr(level(1))
+
+	assert.Contains(t, out, `+String() => "info"`)
+	assert.Contains(t, out, "+Value    => 1")
+}
+
+func TestStringer_Allowlist(t *testing.T) {
+	type entry struct {
+		Level   level
+		Contact *contact
+	}
+	d := New(WithColor(false), WithStringerTypes(reflect.TypeFor[contact]()))
+	out := d.DumpStr(entry{Level: 1, Contact: &contact{Name: "Bob", Email: "b@example.com"}})
+
+	assert.Contains(t, out, "Bob <b@example.com> #*godump.contact")
+	assert.NotContains(t, out, "info")
+	assert.Regexp(t, `\+Level +=> 1`, out)
+}
+
+func TestStringer_RecoversPanics(t *testing.T) {
+	var out string
+	require.NotPanics(t, func() {
+		out = New(WithColor(false)).DumpStr([]brokenStringer{{ID: 1}})
+	})
+
+	assert.Contains(t, out, "0 => <panic: boom> #godump.brokenStringer")
+}
+
+func TestStringer_PanicJSONTree(t *testing.T) {
+	out := DumpJSONTree(brokenStringer{})
+
+	assert.Contains(t, out, `"form": "panic"`)
+	assert.Contains(t, out, `"value": "panic: boom"`)
+}
@@ -0,0 +1,106 @@
+package godump
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode is the set of colors used for ANSI output.
+type ColorMode int
+
+const (
+	// ColorAuto detects the color mode from the environment and the writer.
+	ColorAuto ColorMode = iota
+	// ColorNone disables colors.
+	ColorNone
+	// Color16 uses the 16 standard terminal colors.
+	Color16
+	// Color256 uses the 256-color palette.
+	Color256
+	// ColorTrue uses 24-bit truecolor sequences.
+	ColorTrue
+)
+
+// WithColorMode sets the color mode of ANSI output instead of detecting it.
+func WithColorMode(mode ColorMode) Option {
+	return func(d *Dumper) {
+		d.colorMode = mode
+		d.forceColor = false
+	}
+}
+
+// isTerminal reports whether f is a terminal. It is a variable so tests can stub it.
+var isTerminal = func(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// colorModeFor returns the color mode to use when dumping to w.
+func (d *Dumper) colorModeFor(w io.Writer) ColorMode {
+	switch {
+	case d.colorMode != ColorAuto:
+		return d.colorMode
+	case d.forceColor:
+		return detectColorDepth()
+	default:
+		return detectColorMode(w)
+	}
+}
+
+// detectColorMode determines the color mode for w from the environment,
+// disabling colors for files that are not terminals unless colors are forced.
+func detectColorMode(w io.Writer) ColorMode {
+	if !detectColor() {
+		return ColorNone
+	}
+	if f, ok := w.(*os.File); ok && !isTerminal(f) && !forcedColor() {
+		return ColorNone
+	}
+	return detectColorDepth()
+}
+
+// detectColor checks environment variables to determine if color output should be enabled.
+// NO_COLOR always wins; FORCE_COLOR and CLICOLOR_FORCE override TERM=dumb and CLICOLOR=0.
+func detectColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if forcedColor() {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return os.Getenv("CLICOLOR") != "0"
+}
+
+// forcedColor reports whether the environment asks for colors even when not writing to a terminal.
+func forcedColor() bool {
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
+	}
+	force := os.Getenv("CLICOLOR_FORCE")
+	return force != "" && force != "0"
+}
+
+// detectColorDepth picks the richest color mode the terminal advertises.
+func detectColorDepth() ColorMode {
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	term := os.Getenv("TERM")
+	switch {
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		return ColorTrue
+	case colorTerm != "", strings.Contains(term, "256color"):
+		return Color256
+	case term == "":
+		// Without TERM there is nothing to go on (e.g. Windows consoles or
+		// in-memory writers); 256 colors are supported nearly everywhere.
+		return Color256
+	default:
+		return Color16
+	}
+}
@@ -0,0 +1,141 @@
+package godump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearColorEnv unsets every variable color detection looks at for the duration of the test.
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "TERM", "COLORTERM"} {
+		t.Setenv(key, "")
+	}
+}
+
+// stubTerminal makes isTerminal report the given result for the duration of the test.
+func stubTerminal(t *testing.T, result bool) {
+	t.Helper()
+	orig := isTerminal
+	isTerminal = func(*os.File) bool { return result }
+	t.Cleanup(func() { isTerminal = orig })
+}
+
+func TestDetectColor_Environment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"default", nil, true},
+		{"no color", map[string]string{"NO_COLOR": "1"}, false},
+		{"dumb terminal", map[string]string{"TERM": "dumb"}, false},
+		{"clicolor off", map[string]string{"CLICOLOR": "0"}, false},
+		{"clicolor on", map[string]string{"CLICOLOR": "1"}, true},
+		{"forced over dumb", map[string]string{"TERM": "dumb", "CLICOLOR_FORCE": "1"}, true},
+		{"force disabled", map[string]string{"CLICOLOR": "0", "CLICOLOR_FORCE": "0"}, false},
+		{"no color beats force", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			assert.Equal(t, tt.want, detectColor())
+		})
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		term, colorTerm string
+		want            ColorMode
+	}{
+		{"xterm-256color", "truecolor", ColorTrue},
+		{"xterm", "24bit", ColorTrue},
+		{"xterm-256color", "", Color256},
+		{"screen", "yes", Color256},
+		{"xterm", "", Color16},
+		{"linux", "", Color16},
+		{"", "", Color256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term+"/"+tt.colorTerm, func(t *testing.T) {
+			clearColorEnv(t)
+			t.Setenv("TERM", tt.term)
+			t.Setenv("COLORTERM", tt.colorTerm)
+			assert.Equal(t, tt.want, detectColorDepth())
+		})
+	}
+}
+
+func TestDetectColorMode_Files(t *testing.T) {
+	clearColorEnv(t)
+	t.Setenv("TERM", "xterm-256color")
+
+	stubTerminal(t, false)
+	assert.Equal(t, ColorNone, detectColorMode(os.Stdout))
+	assert.Equal(t, Color256, detectColorMode(&strings.Builder{}), "non-file writers follow the environment")
+
+	t.Setenv("CLICOLOR_FORCE", "1")
+	assert.Equal(t, Color256, detectColorMode(os.Stdout))
+
+	t.Setenv("CLICOLOR_FORCE", "")
+	stubTerminal(t, true)
+	assert.Equal(t, Color256, detectColorMode(os.Stdout))
+}
+
+func TestFdump_FileHasNoEscapeCodes(t *testing.T) {
+	clearColorEnv(t)
+	t.Setenv("TERM", "xterm-256color")
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "dump.txt"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	New().Fdump(f, map[string]int{"a": 1})
+
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "\033[")
+	assert.Contains(t, string(data), `"a" => 1`)
+}
+
+func TestWithColorMode_Sequences(t *testing.T) {
+	theme := Theme{Number: "#ff0000"}
+
+	out := New(WithTheme(theme), WithColorMode(ColorTrue)).DumpStr(1)
+	assert.Contains(t, out, "\033[38;2;255;0;0m1")
+
+	out = New(WithTheme(theme), WithColorMode(Color256)).DumpStr(1)
+	assert.Contains(t, out, "\033[38;5;196m1")
+
+	out = New(WithTheme(theme), WithColorMode(Color16)).DumpStr(1)
+	assert.Contains(t, out, "\033[91m1")
+
+	out = New(WithTheme(theme), WithColorMode(ColorNone)).DumpStr(1)
+	assert.NotContains(t, out, "\033[")
+}
+
+func TestWithColor_ForcesColorOnFiles(t *testing.T) {
+	clearColorEnv(t)
+	t.Setenv("TERM", "xterm-256color")
+	stubTerminal(t, false)
+
+	d := New(WithColor(true))
+	assert.Equal(t, Color256, d.colorModeFor(os.Stdout))
+	assert.Equal(t, ColorNone, New(WithColor(false)).colorModeFor(os.Stdout))
+}
+
+func TestANSI16(t *testing.T) {
+	assert.Equal(t, 30, ansi16(0, 0, 0))
+	assert.Equal(t, 91, ansi16(255, 0, 0))
+	assert.Equal(t, 97, ansi16(255, 255, 255))
+	assert.Equal(t, 32, ansi16(0, 200, 0))
+}
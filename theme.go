@@ -0,0 +1,285 @@
+package godump
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Color is a color in "#rrggbb" notation. The empty Color leaves text unstyled.
+type Color string
+
+// rgb returns the red, green and blue components of the color. It reports
+// false for the empty Color and for malformed values.
+func (c Color) rgb() (r, g, b uint8, ok bool) {
+	if len(c) != 7 || c[0] != '#' {
+		return 0, 0, 0, false
+	}
+	n, err := strconv.ParseUint(string(c[1:]), 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(n >> 16), uint8(n >> 8), uint8(n), true
+}
+
+// Theme assigns colors to the semantic roles of dump output. It is used by
+// both the ANSI and the HTML renderers.
+type Theme struct {
+	Background  Color // page background of HTML output; unused by ANSI output
+	Text        Color // default text color of HTML output; unused by ANSI output
+	Type        Color // type names such as #main.User
+	Field       Color // struct field names
+	Punctuation Color // visibility markers and string quotes
+	String      Color // string contents and hexdump ASCII columns
+	Number      Color // numbers, slice indices and hexdump bytes
+	Bool        Color // true and false
+	Nil         Color // the (nil) of nil values
+	Reference   Color // &N markers and ↩︎ &N back-references
+	Meta        Color // headers, truncation markers and other annotations
+	Key         Color // map keys
+	Offset      Color // hexdump offsets
+	Added       Color // lines added by a diff
+	Removed     Color // lines removed by a diff
+}
+
+// ThemeDark is the default theme, designed for dark terminal backgrounds.
+var ThemeDark = Theme{
+	Background:  "#000000",
+	Text:        "#ffffff",
+	Type:        "#999999",
+	Punctuation: "#ffb400",
+	String:      "#80ff80",
+	Number:      "#40c0ff",
+	Bool:        "#ffb400",
+	Nil:         "#999999",
+	Reference:   "#aaaaaa",
+	Meta:        "#999999",
+	Key:         "#d087d0",
+	Offset:      "#d087d0",
+	Added:       "#5fff5f",
+	Removed:     "#ff5f5f",
+}
+
+// ThemeLight is designed for light terminal backgrounds.
+var ThemeLight = Theme{
+	Background:  "#ffffff",
+	Text:        "#1f2328",
+	Type:        "#6e7781",
+	Punctuation: "#9a6700",
+	String:      "#116329",
+	Number:      "#0550ae",
+	Bool:        "#9a6700",
+	Nil:         "#6e7781",
+	Reference:   "#8c959f",
+	Meta:        "#6e7781",
+	Key:         "#8250df",
+	Offset:      "#8250df",
+	Added:       "#1a7f37",
+	Removed:     "#cf222e",
+}
+
+// ThemeHighContrast uses saturated colors on black for maximum legibility.
+var ThemeHighContrast = Theme{
+	Background:  "#000000",
+	Text:        "#ffffff",
+	Type:        "#ffffff",
+	Field:       "#ffffff",
+	Punctuation: "#ffff00",
+	String:      "#00ff00",
+	Number:      "#00ffff",
+	Bool:        "#ffff00",
+	Nil:         "#ff00ff",
+	Reference:   "#ffffff",
+	Meta:        "#c0c0c0",
+	Key:         "#ff00ff",
+	Offset:      "#ffff00",
+	Added:       "#00ff00",
+	Removed:     "#ff0000",
+}
+
+// ThemeSolarized uses the Solarized dark palette.
+var ThemeSolarized = Theme{
+	Background:  "#002b36",
+	Text:        "#839496",
+	Type:        "#586e75",
+	Field:       "#93a1a1",
+	Punctuation: "#b58900",
+	String:      "#859900",
+	Number:      "#2aa198",
+	Bool:        "#cb4b16",
+	Nil:         "#586e75",
+	Reference:   "#6c71c4",
+	Meta:        "#586e75",
+	Key:         "#d33682",
+	Offset:      "#268bd2",
+	Added:       "#859900",
+	Removed:     "#dc322f",
+}
+
+// WithTheme sets the colors used by colorized and HTML output.
+func WithTheme(theme Theme) Option {
+	return func(d *Dumper) {
+		d.theme = theme
+	}
+}
+
+// role is the semantic role of a piece of rendered text.
+type role int
+
+const (
+	roleNone role = iota
+	roleType
+	roleField
+	rolePunctuation
+	roleString
+	roleNumber
+	roleBool
+	roleNil
+	roleReference
+	roleMeta
+	roleKey
+	roleOffset
+	roleCount
+)
+
+// color returns the color the theme assigns to the role.
+func (t Theme) color(r role) Color {
+	switch r {
+	case roleType:
+		return t.Type
+	case roleField:
+		return t.Field
+	case rolePunctuation:
+		return t.Punctuation
+	case roleString:
+		return t.String
+	case roleNumber:
+		return t.Number
+	case roleBool:
+		return t.Bool
+	case roleNil:
+		return t.Nil
+	case roleReference:
+		return t.Reference
+	case roleMeta:
+		return t.Meta
+	case roleKey:
+		return t.Key
+	case roleOffset:
+		return t.Offset
+	default:
+		return ""
+	}
+}
+
+// ansiStyles returns the escape sequences that open and close each role of the
+// theme in the given color mode.
+func (t Theme) ansiStyles(mode ColorMode) (open, close [roleCount]string) {
+	if mode == ColorNone {
+		return open, close
+	}
+	for i := range roleCount {
+		if seq := ansiColor(t.color(i), mode); seq != "" {
+			open[i], close[i] = seq, ansiReset
+		}
+	}
+	return open, close
+}
+
+// ansiColor returns the escape sequence selecting the color in the given color
+// mode, or "" if the color is empty or malformed.
+func ansiColor(c Color, mode ColorMode) string {
+	r, g, b, ok := c.rgb()
+	if !ok {
+		return ""
+	}
+	switch mode {
+	case ColorTrue:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+	case Color16:
+		return fmt.Sprintf("\033[%dm", ansi16(r, g, b))
+	default:
+		return fmt.Sprintf("\033[38;5;%dm", ansi256(r, g, b))
+	}
+}
+
+// htmlStyles returns the span tags that open and close each role of the theme.
+func (t Theme) htmlStyles() (open, close [roleCount]string) {
+	for i := range roleCount {
+		if _, _, _, ok := t.color(i).rgb(); !ok {
+			continue
+		}
+		open[i] = fmt.Sprintf(`<span style="color:%s">`, t.color(i))
+		close[i] = "</span>"
+	}
+	return open, close
+}
+
+const ansiReset = "\033[0m"
+
+// cubeLevels are the channel intensities of the 6x6x6 color cube of 256-color terminals.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// ansi256 returns the 256-color palette index closest to the given color,
+// picking between the 6x6x6 color cube and the grayscale ramp.
+func ansi256(r, g, b uint8) int {
+	nearestLevel := func(c uint8) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(int(c)-l) < abs(int(c)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := colorDist(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	grayIdx := min(max((avg-8+5)/10, 0), 23)
+	gray := 8 + 10*grayIdx
+	if colorDist(r, g, b, gray, gray, gray) < cubeDist {
+		return 232 + grayIdx
+	}
+	return cube
+}
+
+// ansi16Palette holds the xterm default RGB values of the 16 standard colors.
+var ansi16Palette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ansi16 returns the SGR foreground code (30-37, 90-97) of the standard color
+// closest to the given color.
+func ansi16(r, g, b uint8) int {
+	dist := func(c [3]int) int {
+		return colorDist(r, g, b, c[0], c[1], c[2])
+	}
+	best := 0
+	for i, c := range ansi16Palette {
+		if dist(c) < dist(ansi16Palette[best]) {
+			best = i
+		}
+	}
+	if best < 8 {
+		return 30 + best
+	}
+	return 90 + best - 8
+}
+
+// colorDist returns the squared euclidean distance between two colors.
+func colorDist(r, g, b uint8, r2, g2, b2 int) int {
+	dr, dg, db := int(r)-r2, int(g)-g2, int(b)-b2
+	return dr*dr + dg*dg + db*db
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+package godump
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColor_RGB(t *testing.T) {
+	r, g, b, ok := Color("#0a10ff").rgb()
+	assert.True(t, ok)
+	assert.Equal(t, []uint8{0x0a, 0x10, 0xff}, []uint8{r, g, b})
+
+	for _, c := range []Color{"", "0a10ff", "#0a10f", "#0a10fz"} {
+		_, _, _, ok := c.rgb()
+		assert.False(t, ok, c)
+	}
+}
+
+func TestANSI256(t *testing.T) {
+	assert.Equal(t, 16, ansi256(0, 0, 0))
+	assert.Equal(t, 231, ansi256(255, 255, 255))
+	assert.Equal(t, 196, ansi256(255, 0, 0))
+	assert.Equal(t, 38, ansi256(0, 0xaf, 0xd7))
+	assert.Equal(t, 244, ansi256(0x80, 0x80, 0x80))
+}
+
+func TestThemePresets_AllColorsValid(t *testing.T) {
+	themes := map[string]Theme{
+		"dark":          ThemeDark,
+		"light":         ThemeLight,
+		"high-contrast": ThemeHighContrast,
+		"solarized":     ThemeSolarized,
+	}
+	for name, theme := range themes {
+		for ro := roleType; ro < roleCount; ro++ {
+			c := theme.color(ro)
+			if c == "" {
+				continue
+			}
+			_, _, _, ok := c.rgb()
+			assert.True(t, ok, "%s: role %d has malformed color %q", name, ro, c)
+		}
+	}
+}
+
+func TestWithTheme_ANSI(t *testing.T) {
+	theme := Theme{String: "#ff0000"}
+	out := New(WithColorMode(Color256), WithTheme(theme)).DumpStr("hi")
+
+	assert.Contains(t, out, "\033[38;5;196mhi\033[0m")
+	assert.NotContains(t, out, "\033[38;5;196m\"")
+}
+
+func TestWithTheme_HTML(t *testing.T) {
+	html := New(WithTheme(ThemeLight)).DumpHTML(42)
+
+	assert.Contains(t, html, "background-color:"+string(ThemeLight.Background))
+	assert.Contains(t, html, `<span style="color:`+string(ThemeLight.Number)+`">42</span>`)
+}
+
+func TestThemeColor_UnstyledRoles(t *testing.T) {
+	open, close := ThemeDark.ansiStyles(Color256)
+	assert.Empty(t, open[roleNone])
+	assert.Empty(t, close[roleNone])
+	assert.Empty(t, open[roleField], "dark theme leaves field names in the terminal's color")
+	assert.True(t, strings.HasPrefix(open[roleString], "\033[38;5;"))
+}
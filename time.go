@@ -0,0 +1,153 @@
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	locationType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// timeNow returns the current time for relative annotations. It is a variable
+// so tests can stub it.
+var timeNow = time.Now
+
+// WithTimeFormatting turns the dedicated rendering of time.Time, time.Duration
+// and *time.Location values on or off. When off, they are rendered through
+// their String methods. It is on by default.
+func WithTimeFormatting(enabled bool) Option {
+	return func(d *Dumper) {
+		d.timeFormatting = enabled
+	}
+}
+
+// WithTimeLayout sets the layout used to render time.Time values. Defaults to
+// time.RFC3339Nano.
+func WithTimeLayout(layout string) Option {
+	return func(d *Dumper) {
+		d.timeLayout = layout
+	}
+}
+
+// WithTimeLocation renders time.Time values in loc instead of their own
+// location. A nil loc keeps their own location.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(d *Dumper) {
+		d.timeLocation = loc
+	}
+}
+
+// WithRelativeTime annotates time.Time values with how long ago, or how far
+// in the future, they are, as in "(3m ago)".
+func WithRelativeTime(enabled bool) Option {
+	return func(d *Dumper) {
+		d.relativeTime = enabled
+	}
+}
+
+// WithDurationNanos controls whether time.Duration values are followed by
+// their raw number of nanoseconds. It is on by default.
+func WithDurationNanos(enabled bool) Option {
+	return func(d *Dumper) {
+		d.durationNanos = enabled
+	}
+}
+
+// printTime renders time.Time, time.Duration and *time.Location values, and
+// pointers to times and durations, and reports whether v was one of them.
+func (s *dumpState) printTime(v reflect.Value) bool {
+	if !s.timeFormatting || !v.IsValid() {
+		return false
+	}
+	e := v
+	if e.Kind() == reflect.Ptr && e.Type() != locationType {
+		if e.IsNil() {
+			return false
+		}
+		e = e.Elem()
+	}
+	switch e.Type() {
+	case timeType, durationType, locationType:
+	default:
+		return false
+	}
+	if e.Kind() == reflect.Ptr && e.IsNil() {
+		return false
+	}
+	e = forceExported(e)
+	if !e.CanInterface() {
+		return false
+	}
+
+	text := ""
+	if !s.scanning {
+		switch val := e.Interface().(type) {
+		case time.Time:
+			text = s.formatTime(val)
+		case time.Duration:
+			text = s.formatDuration(val)
+		case *time.Location:
+			text = val.String()
+		}
+	}
+	n := s.node(v)
+	n.Form = FormFormatted
+	s.r.Scalar(n, text)
+	return true
+}
+
+// formatTime renders t with the configured layout, followed by the name of
+// its location unless it is UTC, and by the relative annotation if enabled.
+func (d *Dumper) formatTime(t time.Time) string {
+	if d.timeLocation != nil {
+		t = t.In(d.timeLocation)
+	}
+	text := t.Format(d.timeLayout)
+	if loc := t.Location().String(); loc != "UTC" {
+		text += " " + loc
+	}
+	if d.relativeTime {
+		text += " (" + relativeTime(t, timeNow()) + ")"
+	}
+	return text
+}
+
+// formatDuration renders dur in human units, followed by its raw number of
+// nanoseconds if enabled.
+func (d *Dumper) formatDuration(dur time.Duration) string {
+	if d.durationNanos {
+		return fmt.Sprintf("%s (%dns)", dur, int64(dur))
+	}
+	return dur.String()
+}
+
+// relativeTime describes how far t is from now in the largest whole unit,
+// such as "3m ago" or "in 2d".
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	var amount string
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		amount = fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", d/time.Minute)
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		amount = fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
@@ -0,0 +1,114 @@
+package godump
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTime_RFC3339Nano(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+	out := New(WithColor(false)).DumpStr(ts)
+
+	assert.Contains(t, out, "2024-03-01T12:30:45.123456789Z #time.Time")
+}
+
+func TestTime_NoMonotonicClock(t *testing.T) {
+	out := New(WithColor(false)).DumpStr(time.Now())
+
+	assert.NotContains(t, out, "m=+")
+}
+
+func TestTime_ZoneName(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, loc)
+	out := New(WithColor(false)).DumpStr(ts)
+
+	assert.Contains(t, out, "2024-03-01T12:00:00+01:00 CET #time.Time")
+}
+
+func TestTime_Pointer(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	out := New(WithColor(false)).DumpStr(&ts)
+
+	assert.Contains(t, out, "2024-03-01T00:00:00Z #*time.Time")
+}
+
+func TestTime_LayoutAndLocation(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	out := New(WithColor(false), WithTimeLayout(time.DateTime), WithTimeLocation(time.UTC)).DumpStr(ts)
+
+	assert.Contains(t, out, "2024-03-01 11:00:00 #time.Time")
+}
+
+func TestTime_Relative(t *testing.T) {
+	orig := timeNow
+	defer func() { timeNow = orig }()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	out := New(WithColor(false), WithRelativeTime(true)).DumpStr(now.Add(-3 * time.Minute))
+
+	assert.Contains(t, out, "2024-03-01T11:57:00Z (3m ago)")
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "just now", relativeTime(now, now))
+	assert.Equal(t, "42s ago", relativeTime(now.Add(-42*time.Second), now))
+	assert.Equal(t, "5h ago", relativeTime(now.Add(-5*time.Hour-time.Minute), now))
+	assert.Equal(t, "3d ago", relativeTime(now.Add(-73*time.Hour), now))
+	assert.Equal(t, "in 2m", relativeTime(now.Add(2*time.Minute), now))
+}
+
+func TestDuration(t *testing.T) {
+	d := New(WithColor(false))
+
+	assert.Contains(t, d.DumpStr(90*time.Second), "1m30s (90000000000ns) #time.Duration")
+	assert.Contains(t, d.DumpStr(time.Duration(0)), "0s (0ns) #time.Duration")
+}
+
+func TestDuration_WithoutNanos(t *testing.T) {
+	out := New(WithColor(false), WithDurationNanos(false)).DumpStr(1500 * time.Millisecond)
+
+	assert.Contains(t, out, "1.5s #time.Duration")
+	assert.NotContains(t, out, "ns)")
+}
+
+func TestLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		loc = time.FixedZone("America/New_York", -5*3600)
+	}
+	out := New(WithColor(false)).DumpStr(loc)
+
+	assert.Contains(t, out, "America/New_York #*time.Location")
+}
+
+func TestTime_UnexportedFields(t *testing.T) {
+	type event struct {
+		at      time.Time
+		timeout time.Duration
+	}
+	e := event{at: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), timeout: time.Second}
+	out := New(WithColor(false)).DumpStr(e)
+
+	assert.Contains(t, out, "2024-03-01T00:00:00Z #time.Time")
+	assert.Contains(t, out, "1s (1000000000ns) #time.Duration")
+}
+
+func TestTime_FormattingDisabled(t *testing.T) {
+	out := New(WithColor(false), WithTimeFormatting(false)).DumpStr(90 * time.Second)
+
+	assert.Contains(t, out, "1m30s #time.Duration")
+}
+
+func TestTime_JSONTree(t *testing.T) {
+	out := DumpJSONTree(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	require.Contains(t, out, `"form": "formatted"`)
+	assert.Contains(t, out, `"value": "2024-03-01T00:00:00Z"`)
+}
@@ -0,0 +1,136 @@
+package godump
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	dumpableType = reflect.TypeFor[Dumpable]()
+	errorType    = reflect.TypeFor[error]()
+	stringerType = reflect.TypeFor[fmt.Stringer]()
+)
+
+// ifaceSet is a set of the interfaces the dump treats specially.
+type ifaceSet uint8
+
+const (
+	implDumpable ifaceSet = 1 << iota
+	implError
+	implStringer
+)
+
+// typeInfo is everything about a type that does not depend on a value or on
+// the options of a dump. It is computed once per type and shared by all
+// dumps, concurrent ones included.
+type typeInfo struct {
+	name   string      // display name, as returned by reflect.Type.String
+	impls  ifaceSet    // interfaces the type implements
+	fields []fieldInfo // visible fields, for struct types
+}
+
+// fieldInfo describes a visible field of a struct type.
+type fieldInfo struct {
+	field    reflect.StructField
+	tag      dumpTag
+	exported bool
+	width    int    // length of the name in runes
+	norm     string // normalized name, matched against sensitive names
+}
+
+// typeCache maps types to their *typeInfo.
+var typeCache sync.Map
+
+// infoOf returns the metadata of type t, computing it on first use.
+func infoOf(t reflect.Type) *typeInfo {
+	if info, ok := typeCache.Load(t); ok {
+		return info.(*typeInfo)
+	}
+	info := &typeInfo{name: t.String()}
+	if t.Implements(dumpableType) {
+		info.impls |= implDumpable
+	}
+	if t.Implements(errorType) {
+		info.impls |= implError
+	}
+	if t.Implements(stringerType) {
+		info.impls |= implStringer
+	}
+	if t.Kind() == reflect.Struct {
+		visible := reflect.VisibleFields(t)
+		info.fields = make([]fieldInfo, len(visible))
+		for i, f := range visible {
+			info.fields[i] = fieldInfo{
+				field:    f,
+				tag:      parseDumpTag(f.Tag),
+				exported: f.IsExported(),
+				width:    utf8.RuneCountInString(f.Name),
+				norm:     normalizeName(f.Name),
+			}
+		}
+	}
+	actual, _ := typeCache.LoadOrStore(t, info)
+	return actual.(*typeInfo)
+}
+
+// typeName returns the display name of type t.
+func typeName(t reflect.Type) string {
+	return infoOf(t).name
+}
+
+// cachedFields returns the visible fields of the struct type t.
+func cachedFields(t reflect.Type) []fieldInfo {
+	return infoOf(t).fields
+}
+
+// implements reports whether the value held by v, looking through an
+// interface, implements all of impls. Unlike a type assertion on
+// v.Interface(), it does not allocate.
+func implements(v reflect.Value, impls ifaceSet) bool {
+	t := v.Type()
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		t = v.Elem().Type()
+	}
+	return infoOf(t).impls&impls == impls
+}
+
+// refsCache maps types to whether mayHoldRefs holds for them.
+var refsCache sync.Map
+
+// mayHoldRefs reports whether a value of type t may be, or contain, a value
+// that can be reached more than once, so the scanning pass has to walk it.
+// Errors may wrap such values.
+func mayHoldRefs(t reflect.Type) bool {
+	if held, ok := refsCache.Load(t); ok {
+		return held.(bool)
+	}
+	held := computeMayHoldRefs(t)
+	refsCache.Store(t, held)
+	return held
+}
+
+func computeMayHoldRefs(t reflect.Type) bool {
+	if infoOf(t).impls&implError != 0 {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && mayHoldRefs(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if mayHoldRefs(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
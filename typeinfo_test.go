@@ -0,0 +1,74 @@
+package godump
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cachedUser struct {
+	Name     string
+	Password string `dump:"redact"`
+	secret   string
+	Stamp    cachedStamp
+}
+
+type cachedStamp int
+
+func (s cachedStamp) String() string { return "stamp" }
+
+func TestInfoOf_CachesPerType(t *testing.T) {
+	typ := reflect.TypeFor[cachedUser]()
+	info := infoOf(typ)
+
+	assert.Same(t, info, infoOf(typ))
+	assert.Equal(t, "godump.cachedUser", info.name)
+	require.Len(t, info.fields, 4)
+	assert.True(t, info.fields[0].exported)
+	assert.True(t, info.fields[1].tag.redact)
+	assert.False(t, info.fields[2].exported)
+	assert.Equal(t, len("Password"), info.fields[1].width)
+	assert.Equal(t, "password", info.fields[1].norm)
+}
+
+func TestInfoOf_Interfaces(t *testing.T) {
+	assert.Equal(t, implStringer, infoOf(reflect.TypeFor[cachedStamp]()).impls)
+	assert.Equal(t, implError, infoOf(reflect.TypeOf(errors.New("x"))).impls)
+	assert.Equal(t, implDumpable, infoOf(reflect.TypeFor[selfDumpable]()).impls)
+	assert.Zero(t, infoOf(reflect.TypeFor[int]()).impls)
+
+	var err error = errors.New("x")
+	assert.True(t, implements(reflect.ValueOf(&err).Elem(), implError))
+	var nilErr error
+	assert.False(t, implements(reflect.ValueOf(&nilErr).Elem(), implError))
+}
+
+func TestFdump_ConcurrentDumpsShareTypeCache(t *testing.T) {
+	users := []cachedUser{{Name: "a", Password: "p", secret: "s"}, {Name: "b"}}
+	d := New(WithColor(false))
+	body := func() string {
+		_, out, _ := strings.Cut(d.DumpStr(users), "\n") // drop the caller header
+		return out
+	}
+	want := body()
+
+	var wg sync.WaitGroup
+	got := make([]string, 16)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = body()
+		}()
+	}
+	wg.Wait()
+
+	for _, out := range got {
+		assert.Equal(t, want, out)
+	}
+}